@@ -0,0 +1,159 @@
+package libsvm
+
+/*
+#include <svm.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// ProblemBuilder assembles an SvmProblem one example at a time, so training
+// data can be streamed from a database cursor or generator without first
+// holding it all in [][]float64. Labels and nodes are written straight into
+// C arrays that grow as needed. A builder is not safe for concurrent use.
+type ProblemBuilder struct {
+	y       *C.double
+	space   *C.struct_svm_node
+	yCap    int
+	nodes   int
+	nodeCap int
+	rows    []int // offset into space at which each example starts
+}
+
+// NewProblemBuilder returns an empty builder. Any C memory it holds is
+// handed to the problem returned by Build, or released by a finalizer if the
+// builder is discarded first.
+func NewProblemBuilder() *ProblemBuilder {
+	b := &ProblemBuilder{}
+	runtime.SetFinalizer(b, (*ProblemBuilder).reset)
+	return b
+}
+
+// Len returns the number of examples added so far
+func (b *ProblemBuilder) Len() int {
+	return len(b.rows)
+}
+
+// AddExample appends a dense example whose features are indexed from 1, as
+// NewProblem does. NaN and infinite features are rejected.
+func (b *ProblemBuilder) AddExample(label float64, features []float64) error {
+	if len(features) == 0 {
+		return SvmError{Message: fmt.Sprintf("example %d is empty", len(b.rows))}
+	}
+
+	for i, v := range features {
+		if err := checkFinite(i+1, v); err != nil {
+			return err
+		}
+	}
+
+	space := b.addRow(label, len(features))
+	for i, v := range features {
+		space[i].index = C.int(i + 1)
+		space[i].value = C.double(v)
+	}
+
+	return nil
+}
+
+// AddSparse appends a sparse example with the given strictly increasing,
+// positive feature indices. NaN and infinite values are rejected.
+func (b *ProblemBuilder) AddSparse(label float64, indices []int, values []float64) error {
+	if len(indices) != len(values) {
+		return SvmError{Message: fmt.Sprintf("index count %d does not match value count %d", len(indices), len(values))}
+	}
+
+	if err := checkIndices(indices); err != nil {
+		return err
+	}
+
+	for i, v := range values {
+		if err := checkFinite(indices[i], v); err != nil {
+			return err
+		}
+	}
+
+	space := b.addRow(label, len(indices))
+	for i, idx := range indices {
+		space[i].index = C.int(idx)
+		space[i].value = C.double(values[i])
+	}
+
+	return nil
+}
+
+// addRow records a new example with room for n features and returns its
+// nodes, with the terminator already in place
+func (b *ProblemBuilder) addRow(label float64, n int) []C.struct_svm_node {
+	if len(b.rows) == b.yCap {
+		b.yCap = grow(b.yCap, len(b.rows)+1)
+		b.y = (*C.double)(C.realloc(unsafe.Pointer(b.y), C.size_t(b.yCap)*C.sizeof_double))
+	}
+
+	if b.nodes+n+1 > b.nodeCap {
+		b.nodeCap = grow(b.nodeCap, b.nodes+n+1)
+		b.space = (*C.struct_svm_node)(C.realloc(unsafe.Pointer(b.space), C.size_t(b.nodeCap)*C.sizeof_struct_svm_node))
+	}
+
+	unsafe.Slice(b.y, b.yCap)[len(b.rows)] = C.double(label)
+	b.rows = append(b.rows, b.nodes)
+
+	space := unsafe.Slice(b.space, b.nodeCap)[b.nodes : b.nodes+n+1]
+	space[n].index = -1
+	space[n].value = 0
+	b.nodes += n + 1
+
+	return space[:n]
+}
+
+// grow returns a capacity of at least need, doubling from have
+func grow(have, need int) int {
+	if have < 64 {
+		have = 64
+	}
+	for have < need {
+		have *= 2
+	}
+
+	return have
+}
+
+// Build returns a problem holding every example added so far and leaves the
+// builder empty, ready for reuse. The problem must be released with
+// FreeProblem as usual.
+func (b *ProblemBuilder) Build() (*SvmProblem, error) {
+	l := len(b.rows)
+	if l == 0 {
+		return nil, SvmError{Message: "no examples when attempting to build an svm problem"}
+	}
+
+	obj := (*C.struct_svm_problem)(C.calloc(1, C.sizeof_struct_svm_problem))
+	obj.l = C.int(l)
+	obj.y = b.y
+	obj.x = (**C.struct_svm_node)(C.calloc(C.size_t(l), C.size_t(unsafe.Sizeof((*C.struct_svm_node)(nil)))))
+
+	xs := unsafe.Slice(obj.x, l)
+	space := unsafe.Slice(b.space, b.nodes)
+	for i, off := range b.rows {
+		xs[i] = &space[off]
+	}
+
+	prob := &SvmProblem{object: obj, space: b.space}
+
+	b.y, b.space = nil, nil
+	b.reset()
+
+	return prob, nil
+}
+
+// reset releases any memory still owned by the builder
+func (b *ProblemBuilder) reset() {
+	C.free(unsafe.Pointer(b.y))
+	C.free(unsafe.Pointer(b.space))
+	*b = ProblemBuilder{}
+}
@@ -0,0 +1,77 @@
+package libsvm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestProblemBuilder(t *testing.T) {
+	labels, X := threeClassData()
+
+	b := NewProblemBuilder()
+	for i, x := range X {
+		var err error
+		if i%2 == 0 {
+			err = b.AddExample(labels[i], x)
+		} else {
+			err = b.AddSparse(labels[i], []int{1, 2}, x)
+		}
+		if err != nil {
+			t.Fatal("Add error was non-nil", err)
+		}
+	}
+
+	if b.Len() != len(X) {
+		t.Error("Unexpected builder length", b.Len())
+	}
+
+	built, err := b.Build()
+	if err != nil {
+		t.Fatal("Build error was non-nil", err)
+	}
+	defer FreeProblem(built)
+
+	if b.Len() != 0 {
+		t.Error("Expected Build to empty the builder, length is", b.Len())
+	}
+
+	param := NewParameter(C_SVC, RBF)
+	param.SetGamma(0.5)
+	defer FreeParam(param)
+
+	ref := NewParameter(C_SVC, RBF)
+	ref.SetGamma(0.5)
+	want, prob := trainThreeClass(t, ref)
+	defer FreeProblem(prob)
+	defer FreeModel(want)
+
+	got, err := Train(*built, *param)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(got)
+
+	if got.TotalSv() != want.TotalSv() {
+		t.Errorf("Built problem trained %d support vectors, NewProblem trained %d", got.TotalSv(), want.TotalSv())
+	}
+
+	for _, x := range X {
+		a, _ := got.PredictDense(x)
+		e, _ := want.PredictDense(x)
+		if a != e {
+			t.Errorf("Prediction for %v was %f, expected %f", x, a, e)
+		}
+	}
+
+	if err := b.AddExample(1, []float64{math.NaN()}); err == nil {
+		t.Error("Expected an error adding a NaN feature")
+	}
+
+	if err := b.AddSparse(1, []int{2, 1}, []float64{1, 1}); err == nil {
+		t.Error("Expected an error adding unordered indices")
+	}
+
+	if _, err := b.Build(); err == nil {
+		t.Error("Expected an error building an empty problem")
+	}
+}
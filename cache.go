@@ -0,0 +1,135 @@
+package libsvm
+
+import (
+	"container/list"
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+// CachingModel wraps a model with a least recently used cache of
+// predictions, keyed by a hash of the example's features, for workloads that
+// score the same vectors repeatedly. Cached entries keep a copy of their
+// features, so a hash collision is treated as a miss rather than returning
+// another example's prediction. It is safe for concurrent use. The wrapped
+// model is not owned by the cache and must outlive it.
+type CachingModel struct {
+	model *SvmModel
+	size  int
+
+	mu      sync.Mutex
+	order   *list.List // most recently used at the front
+	entries map[uint64]*list.Element
+}
+
+// cacheEntry is a cached prediction
+type cacheEntry struct {
+	key      uint64
+	features []Pair
+	label    float64
+}
+
+// NewCachingModel wraps mdl with a cache holding up to size predictions
+func NewCachingModel(mdl *SvmModel, size int) *CachingModel {
+	return &CachingModel{
+		model:   mdl,
+		size:    size,
+		order:   list.New(),
+		entries: make(map[uint64]*list.Element),
+	}
+}
+
+// Predict returns the cached prediction for the node's features, or predicts
+// them with the wrapped model and caches the result. Errors are not cached.
+func (cm *CachingModel) Predict(node *SvmNode) (float64, error) {
+	mdl := cm.model
+	if mdl != nil {
+		mdl.mu.RLock()
+		defer mdl.mu.RUnlock()
+	}
+
+	if err := checkPredict(mdl, node, "predict using a caching model"); err != nil {
+		return -1, err
+	}
+
+	features := node.Pairs()
+	key := hashPairs(features)
+
+	cm.mu.Lock()
+	if el, ok := cm.entries[key]; ok && equalPairs(el.Value.(*cacheEntry).features, features) {
+		cm.order.MoveToFront(el)
+		label := el.Value.(*cacheEntry).label
+		cm.mu.Unlock()
+		return label, nil
+	}
+	cm.mu.Unlock()
+
+	label, err := mdl.predict(node)
+	if err != nil {
+		return label, err
+	}
+
+	cm.add(&cacheEntry{key: key, features: features, label: label})
+	return label, nil
+}
+
+// Len returns the number of cached predictions
+func (cm *CachingModel) Len() int {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	return cm.order.Len()
+}
+
+// add caches an entry, replacing any with the same key and evicting the
+// least recently used entry when the cache is full
+func (cm *CachingModel) add(entry *cacheEntry) {
+	if cm.size <= 0 {
+		return
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if el, ok := cm.entries[entry.key]; ok {
+		el.Value = entry
+		cm.order.MoveToFront(el)
+		return
+	}
+
+	if cm.order.Len() >= cm.size {
+		oldest := cm.order.Back()
+		cm.order.Remove(oldest)
+		delete(cm.entries, oldest.Value.(*cacheEntry).key)
+	}
+
+	cm.entries[entry.key] = cm.order.PushFront(entry)
+}
+
+// hashPairs hashes features with 64-bit FNV-1a
+func hashPairs(pairs []Pair) uint64 {
+	h := fnv.New64a()
+	var buf [16]byte
+	for _, p := range pairs {
+		binary.LittleEndian.PutUint64(buf[:8], uint64(p.Index))
+		binary.LittleEndian.PutUint64(buf[8:], math.Float64bits(p.Value))
+		h.Write(buf[:])
+	}
+
+	return h.Sum64()
+}
+
+// equalPairs reports whether two feature lists are identical
+func equalPairs(a, b []Pair) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
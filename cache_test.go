@@ -0,0 +1,69 @@
+package libsvm
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCachingModel(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	_, examples := readDenseData(t, "testdata/a1a", 123)
+	cm := NewCachingModel(mdl, 10)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, ex := range examples[:10] {
+				exa := NewExample(1, ex)
+				want, _ := mdl.Predict(exa)
+				first, err := cm.Predict(exa)
+				if err != nil {
+					t.Error("Predict error was non-nil", err)
+				}
+				cached, _ := cm.Predict(exa)
+				exa.Free()
+
+				if first != want || cached != want {
+					t.Errorf("Caching model predicted %f then %f, expected %f", first, cached, want)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if cm.Len() != 10 {
+		t.Error("Expected 10 cached predictions, got", cm.Len())
+	}
+
+	// touch the first example so that the second is the least recently used
+	first := NewExample(1, examples[0])
+	defer first.Free()
+	cm.Predict(first)
+
+	extra := NewExample(1, examples[10])
+	defer extra.Free()
+	cm.Predict(extra)
+
+	if cm.Len() != 10 {
+		t.Error("Expected the cache to stay at capacity, got", cm.Len())
+	}
+
+	second := NewExample(1, examples[1])
+	defer second.Free()
+
+	cm.mu.Lock()
+	_, firstCached := cm.entries[hashPairs(first.Pairs())]
+	_, secondCached := cm.entries[hashPairs(second.Pairs())]
+	cm.mu.Unlock()
+
+	if !firstCached || secondCached {
+		t.Errorf("Expected the least recently used entry to be evicted, first cached %t, second cached %t", firstCached, secondCached)
+	}
+}
@@ -0,0 +1,204 @@
+package libsvm
+
+// Option configures the convenience estimators such as Classifier
+type Option func(*config)
+
+// config holds the settings shared by the convenience estimators
+type config struct {
+	svmType  SvmType
+	kernel   KernelType
+	c        float64
+	nu       float64
+	p        float64
+	gamma    float64
+	gammaSet bool
+	nuSVR    bool
+	// err records an option that does not apply to the estimator, returned
+	// by Fit
+	err error
+}
+
+// WithKernel selects the kernel type. The default is RBF.
+func WithKernel(kernel KernelType) Option {
+	return func(cfg *config) {
+		cfg.kernel = kernel
+	}
+}
+
+// WithC sets the cost parameter. The default is 1.
+func WithC(c float64) Option {
+	return func(cfg *config) {
+		cfg.c = c
+	}
+}
+
+// WithGamma sets the kernel gamma. By default gamma is 1/num_features, where
+// the number of features is taken from the widest training example.
+func WithGamma(gamma float64) Option {
+	return func(cfg *config) {
+		cfg.gamma = gamma
+		cfg.gammaSet = true
+	}
+}
+
+// WithEpsilon sets the width of the insensitive tube of an EPSILON_SVR
+// Regressor. The default is 0.1.
+func WithEpsilon(p float64) Option {
+	return func(cfg *config) {
+		cfg.p = p
+	}
+}
+
+// WithNuSVR makes a Regressor or MultiRegressor use NU_SVR with the given
+// nu, in (0, 1], instead of EPSILON_SVR. Other estimators reject it from Fit.
+func WithNuSVR(nu float64) Option {
+	return func(cfg *config) {
+		cfg.nuSVR = true
+		cfg.nu = nu
+	}
+}
+
+// newConfig applies opts on top of the defaults for the given svm type
+func newConfig(svmType SvmType, opts []Option) config {
+	cfg := config{
+		svmType: svmType,
+		kernel:  RBF,
+		c:       1,
+		nu:      0.5,
+		p:       0.1,
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.nuSVR {
+		if svmType == EPSILON_SVR {
+			cfg.svmType = NU_SVR
+		} else {
+			cfg.err = SvmError{Kind: ErrInvalidParameter, Message: "WithNuSVR only applies to regressors, not a " + svmType.String() + " estimator"}
+		}
+	}
+
+	return cfg
+}
+
+// parameter allocates an svm_parameter for the configuration, defaulting
+// gamma based on the number of features in X
+func (cfg config) parameter(X [][]float64) *SvmParameter {
+	param := NewParameter(cfg.svmType, cfg.kernel)
+	param.SetC(cfg.c)
+	param.SetNu(cfg.nu)
+	param.SetP(cfg.p)
+
+	if cfg.gammaSet {
+		param.SetGamma(cfg.gamma)
+	} else {
+		width := 0
+		for _, x := range X {
+			if len(x) > width {
+				width = len(x)
+			}
+		}
+		if width > 0 {
+			param.SetGamma(1.0 / float64(width))
+		}
+	}
+
+	return param
+}
+
+// estimator owns the problem, parameter and model behind a convenience type.
+// The problem is kept for as long as the model, since a trained model
+// references the problem's nodes for its support vectors.
+type estimator struct {
+	prob  *SvmProblem
+	param *SvmParameter
+	model *SvmModel
+}
+
+// fit trains a new model, releasing any previous one first
+func (est *estimator) fit(cfg config, labels []float64, X [][]float64) error {
+	est.close()
+
+	if cfg.err != nil {
+		return cfg.err
+	}
+
+	prob, err := NewProblem(labels, X)
+	if err != nil {
+		return err
+	}
+
+	param := cfg.parameter(X)
+	mdl, err := Train(*prob, *param)
+	if err != nil {
+		FreeParam(param)
+		FreeProblem(prob)
+		return err
+	}
+
+	est.prob = prob
+	est.param = param
+	est.model = mdl
+	return nil
+}
+
+// predict predicts a single dense example
+func (est *estimator) predict(x []float64) (float64, error) {
+	if est.model == nil {
+		return -1, SvmError{Message: "model has not been fit when attempting to predict"}
+	}
+
+	node := NewExample(1, x)
+	defer node.Free()
+
+	return est.model.Predict(node)
+}
+
+// close releases the model, parameter and problem
+func (est *estimator) close() {
+	if est.model != nil {
+		FreeModel(est.model)
+		est.model = nil
+	}
+
+	if est.param != nil {
+		FreeParam(est.param)
+		est.param = nil
+	}
+
+	if est.prob != nil {
+		FreeProblem(est.prob)
+		est.prob = nil
+	}
+}
+
+// Classifier is a convenience wrapper that trains a C_SVC model from dense Go
+// slices and owns the underlying C resources, which are released by Close.
+type Classifier struct {
+	cfg config
+	est estimator
+}
+
+// NewClassifier creates a classifier configured by opts
+func NewClassifier(opts ...Option) *Classifier {
+	return &Classifier{cfg: newConfig(C_SVC, opts)}
+}
+
+// Fit trains the classifier, where labels[i] is the class of X[i].
+// Calling Fit again replaces the previously trained model.
+func (clf *Classifier) Fit(labels []float64, X [][]float64) error {
+	return clf.est.fit(clf.cfg, labels, X)
+}
+
+// Predict returns the predicted class of x
+func (clf *Classifier) Predict(x []float64) (float64, error) {
+	return clf.est.predict(x)
+}
+
+// Close releases the C resources owned by the classifier
+func (clf *Classifier) Close() error {
+	clf.est.close()
+	return nil
+}
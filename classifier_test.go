@@ -0,0 +1,79 @@
+package libsvm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassifier(t *testing.T) {
+	clf := NewClassifier(WithKernel(LINEAR), WithC(10))
+	defer clf.Close()
+
+	if _, err := clf.Predict([]float64{1, 1}); err == nil {
+		t.Error("Expected an error predicting before Fit")
+	}
+
+	labels := []float64{1, 1, 1, -1, -1, -1}
+	X := [][]float64{{2, 2}, {1, 2}, {2, 1}, {-2, -2}, {-1, -2}, {-2, -1}}
+	if err := clf.Fit(labels, X); err != nil {
+		t.Fatal("Fit error was non-nil", err)
+	}
+
+	for _, c := range []struct {
+		x    []float64
+		want float64
+	}{{[]float64{3, 3}, 1}, {[]float64{-3, -3}, -1}} {
+		got, err := clf.Predict(c.x)
+		if err != nil {
+			t.Fatal("Predict error was non-nil", err)
+		}
+
+		if got != c.want {
+			t.Errorf("Predicted %f for %v, expected %f", got, c.x, c.want)
+		}
+	}
+
+	if err := clf.Close(); err != nil {
+		t.Error("Close error was non-nil", err)
+	}
+
+	if _, err := clf.Predict([]float64{1, 1}); err == nil {
+		t.Error("Expected an error predicting after Close")
+	}
+}
+
+func TestClassifierGamma(t *testing.T) {
+	clf := NewClassifier(WithGamma(0.25))
+	defer clf.Close()
+
+	if err := clf.Fit([]float64{1, -1}, [][]float64{{1, 0}, {0, 1}}); err != nil {
+		t.Fatal("Fit error was non-nil", err)
+	}
+
+	if clf.est.param.object.gamma != 0.25 {
+		t.Error("Expected gamma option to be applied, got", clf.est.param.object.gamma)
+	}
+}
+
+func TestWithNuSVRRejected(t *testing.T) {
+	labels := []float64{1, 1, -1, -1}
+	X := [][]float64{{1, 1}, {1, 0.8}, {-1, -1}, {-0.8, -1}}
+
+	clf := NewClassifier(WithNuSVR(0.5))
+	defer clf.Close()
+	if err := clf.Fit(labels, X); err == nil || !strings.Contains(err.Error(), "WithNuSVR") {
+		t.Error("Expected WithNuSVR to be rejected by a classifier, got", err)
+	}
+
+	nd := NewNoveltyDetector(WithNuSVR(0.5))
+	defer nd.Close()
+	if err := nd.Fit(X, 0.1); err == nil {
+		t.Error("Expected WithNuSVR to be rejected by a novelty detector")
+	}
+
+	reg := NewRegressor(WithNuSVR(0.5))
+	defer reg.Close()
+	if err := reg.Fit(labels, X); err != nil || reg.est.model.SvmType() != NU_SVR {
+		t.Error("Expected WithNuSVR to train a NU_SVR regressor", err)
+	}
+}
@@ -0,0 +1,203 @@
+package libsvm
+
+/*
+#include <svm.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"unsafe"
+)
+
+// CrossValidationParallel is like CrossValidation, but trains the nrFold
+// models concurrently on up to workers goroutines, using every CPU when
+// workers is not positive. Folds are assigned by a fixed pseudo-random
+// shuffle, so results are reproducible but differ slightly from the
+// sequential version, which uses LIBSVM's own fold assignment.
+func CrossValidationParallel(prob SvmProblem, param SvmParameter, nrFold, workers int) ([]float64, error) {
+	if nrFold < 2 {
+		return nil, SvmError{Message: fmt.Sprintf("invalid number of folds %d, at least 2 are required for cross validation", nrFold)}
+	}
+
+	if err := checkParams(prob, param); err != nil {
+		return nil, err
+	}
+
+	l := int(prob.object.l)
+	if nrFold > l {
+		nrFold = l
+	}
+
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	folds := make([][]int, nrFold)
+	for i, idx := range rand.New(rand.NewSource(1)).Perm(l) {
+		folds[i%nrFold] = append(folds[i%nrFold], idx)
+	}
+
+	target := make([]float64, l)
+	errs := make([]error, nrFold)
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range jobs {
+				errs[f] = predictFold(prob, param, folds, f, target)
+			}
+		}()
+	}
+
+	for f := range folds {
+		jobs <- f
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return target, nil
+}
+
+// StratifiedFolds splits the example indices 0 to len(labels)-1 into k folds
+// whose class proportions match those of the whole set as closely as the
+// class sizes allow, which LIBSVM's own cross validation only does for
+// classification problems and does not expose. Examples are assigned by a
+// pseudo-random shuffle seeded with seed, so the folds are reproducible.
+// Each fold's indices are in ascending order.
+func StratifiedFolds(labels []float64, k int, seed int64) ([][]int, error) {
+	if k < 2 {
+		return nil, SvmError{Message: fmt.Sprintf("invalid number of folds %d, at least 2 are required", k)}
+	}
+
+	if k > len(labels) {
+		return nil, SvmError{Message: fmt.Sprintf("%d folds requested for only %d examples", k, len(labels))}
+	}
+
+	classes := make(map[float64][]int)
+	var order []float64
+	for i, label := range labels {
+		if _, ok := classes[label]; !ok {
+			order = append(order, label)
+		}
+		classes[label] = append(classes[label], i)
+	}
+	sort.Float64s(order)
+
+	rnd := rand.New(rand.NewSource(seed))
+	folds := make([][]int, k)
+	next := 0
+	for _, label := range order {
+		members := classes[label]
+		rnd.Shuffle(len(members), func(i, j int) {
+			members[i], members[j] = members[j], members[i]
+		})
+
+		// dealing on from where the previous class stopped keeps the fold
+		// sizes within one of each other
+		for _, idx := range members {
+			folds[next] = append(folds[next], idx)
+			next = (next + 1) % k
+		}
+	}
+
+	for _, fold := range folds {
+		sort.Ints(fold)
+	}
+
+	return folds, nil
+}
+
+// TrainTestSplit shuffles the examples with a pseudo-random permutation
+// seeded with seed and splits them into a training set and a test set
+// holding round(testFraction*len(labels)) examples, at least one of each.
+// The returned slices share the rows of X rather than copying them.
+func TrainTestSplit(labels []float64, X [][]float64, testFraction float64, seed int64) (trainL, testL []float64, trainX, testX [][]float64, err error) {
+	if len(labels) != len(X) {
+		return nil, nil, nil, nil, SvmError{Message: fmt.Sprintf("label count %d does not match example count %d", len(labels), len(X))}
+	}
+
+	if !(testFraction > 0 && testFraction < 1) {
+		return nil, nil, nil, nil, SvmError{Message: fmt.Sprintf("invalid test fraction %g, must be between 0 and 1", testFraction)}
+	}
+
+	if len(labels) < 2 {
+		return nil, nil, nil, nil, SvmError{Message: fmt.Sprintf("%d examples cannot be split into training and test sets", len(labels))}
+	}
+
+	nTest := int(math.Round(testFraction * float64(len(labels))))
+	if nTest < 1 {
+		nTest = 1
+	} else if nTest > len(labels)-1 {
+		nTest = len(labels) - 1
+	}
+
+	for i, idx := range rand.New(rand.NewSource(seed)).Perm(len(labels)) {
+		if i < nTest {
+			testL = append(testL, labels[idx])
+			testX = append(testX, X[idx])
+		} else {
+			trainL = append(trainL, labels[idx])
+			trainX = append(trainX, X[idx])
+		}
+	}
+
+	return trainL, testL, trainX, testX, nil
+}
+
+// predictFold trains on every fold but f and writes the predictions for the
+// examples of fold f into target
+func predictFold(prob SvmProblem, param SvmParameter, folds [][]int, f int, target []float64) error {
+	var train []int
+	for g, fold := range folds {
+		if g != f {
+			train = append(train, fold...)
+		}
+	}
+
+	sub := subProblem(prob, train)
+	defer FreeProblem(sub)
+
+	mdl, err := trainUnchecked(*sub, param)
+	if err != nil {
+		return err
+	}
+	defer FreeModel(mdl)
+
+	xs := unsafe.Slice(prob.object.x, int(prob.object.l))
+	for _, idx := range folds[f] {
+		target[idx] = float64(C.svm_predict(mdl.object, xs[idx]))
+	}
+
+	return nil
+}
+
+// subProblem builds a problem holding the given rows of prob. The rows share
+// prob's nodes, so prob must outlive the returned problem.
+func subProblem(prob SvmProblem, rows []int) *SvmProblem {
+	sub := allocProblem(len(rows), 0)
+	ys := unsafe.Slice(prob.object.y, int(prob.object.l))
+	xs := unsafe.Slice(prob.object.x, int(prob.object.l))
+	subYs := unsafe.Slice(sub.object.y, len(rows))
+	subXs := unsafe.Slice(sub.object.x, len(rows))
+	for i, idx := range rows {
+		subYs[i] = ys[idx]
+		subXs[i] = xs[idx]
+	}
+
+	return sub
+}
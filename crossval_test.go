@@ -0,0 +1,166 @@
+package libsvm
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func a1aProblem(tb testing.TB) (*SvmProblem, *SvmParameter, []float64) {
+	labels, examples := readDenseData(tb, "testdata/a1a", 123)
+	prob, err := NewProblem(labels, examples)
+	if err != nil {
+		tb.Fatal("NewProblem error was non-nil", err)
+	}
+
+	param := NewParameter(C_SVC, RBF)
+	param.SetGammaAuto(123)
+
+	return prob, param, labels
+}
+
+func TestCrossValidationParallel(t *testing.T) {
+	prob, param, labels := a1aProblem(t)
+	defer FreeProblem(prob)
+	defer FreeParam(param)
+
+	sequential, err := CrossValidation(*prob, *param, 5)
+	if err != nil {
+		t.Fatal("CrossValidation error was non-nil", err)
+	}
+
+	parallel, err := CrossValidationParallel(*prob, *param, 5, 4)
+	if err != nil {
+		t.Fatal("CrossValidationParallel error was non-nil", err)
+	}
+
+	if len(parallel) != len(labels) {
+		t.Fatal("Expected one prediction per example, got", len(parallel))
+	}
+
+	seqAcc, _ := Accuracy(sequential, labels)
+	parAcc, _ := Accuracy(parallel, labels)
+	if math.Abs(seqAcc-parAcc) > 0.03 {
+		t.Errorf("Parallel accuracy %f differs from sequential accuracy %f", parAcc, seqAcc)
+	}
+
+	if _, err := CrossValidationParallel(*prob, *param, 1, 4); err == nil {
+		t.Error("Expected an error for fewer than 2 folds")
+	}
+}
+
+func BenchmarkCrossValidation(b *testing.B) {
+	prob, param, _ := a1aProblem(b)
+	defer FreeProblem(prob)
+	defer FreeParam(param)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		CrossValidation(*prob, *param, 5)
+	}
+}
+
+func BenchmarkCrossValidationParallel(b *testing.B) {
+	prob, param, _ := a1aProblem(b)
+	defer FreeProblem(prob)
+	defer FreeParam(param)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		CrossValidationParallel(*prob, *param, 5, 0)
+	}
+}
+
+func TestStratifiedFolds(t *testing.T) {
+	var labels []float64
+	for i := 0; i < 100; i++ {
+		if i%10 == 3 {
+			labels = append(labels, 1)
+		} else {
+			labels = append(labels, -1)
+		}
+	}
+
+	folds, err := StratifiedFolds(labels, 5, 7)
+	if err != nil {
+		t.Fatal("StratifiedFolds error was non-nil", err)
+	}
+
+	seen := make(map[int]bool)
+	for _, fold := range folds {
+		positives := 0
+		for _, idx := range fold {
+			if seen[idx] {
+				t.Error("Index assigned to more than one fold", idx)
+			}
+			seen[idx] = true
+			if labels[idx] == 1 {
+				positives++
+			}
+		}
+
+		if ratio := float64(positives) / float64(len(fold)); math.Abs(ratio-0.1) > 0.02 {
+			t.Errorf("Fold positive ratio %f is not close to the global 0.1", ratio)
+		}
+	}
+
+	if len(seen) != len(labels) {
+		t.Errorf("Folds covered %d of %d examples", len(seen), len(labels))
+	}
+
+	again, _ := StratifiedFolds(labels, 5, 7)
+	if fmt.Sprint(again) != fmt.Sprint(folds) {
+		t.Error("Expected the same seed to give the same folds")
+	}
+
+	if _, err := StratifiedFolds(labels, 1, 7); err == nil {
+		t.Error("Expected an error for a single fold")
+	}
+}
+
+func TestTrainTestSplit(t *testing.T) {
+	labels := make([]float64, 50)
+	X := make([][]float64, 50)
+	for i := range labels {
+		labels[i] = float64(i % 2)
+		X[i] = []float64{float64(i)}
+	}
+
+	trainL, testL, trainX, testX, err := TrainTestSplit(labels, X, 0.2, 11)
+	if err != nil {
+		t.Fatal("TrainTestSplit error was non-nil", err)
+	}
+
+	if len(trainL) != 40 || len(trainX) != 40 || len(testL) != 10 || len(testX) != 10 {
+		t.Fatalf("Unexpected split sizes %d/%d train and %d/%d test", len(trainL), len(trainX), len(testL), len(testX))
+	}
+
+	allL := append(append([]float64{}, trainL...), testL...)
+	allX := append(append([][]float64{}, trainX...), testX...)
+	seen := make(map[float64]bool)
+	for i, x := range allX {
+		if seen[x[0]] {
+			t.Error("Example appeared twice", x)
+		}
+		seen[x[0]] = true
+
+		if label := allL[i]; label != labels[int(x[0])] {
+			t.Error("Label no longer matches its example", x, label)
+		}
+	}
+
+	if len(seen) != 50 {
+		t.Error("Expected every example in exactly one set, got", len(seen))
+	}
+
+	_, again, _, _, _ := TrainTestSplit(labels, X, 0.2, 11)
+	if fmt.Sprint(again) != fmt.Sprint(testL) {
+		t.Error("Expected the same seed to give the same split")
+	}
+
+	for _, f := range []float64{0, 1, -0.5, math.NaN()} {
+		if _, _, _, _, err := TrainTestSplit(labels, X, f, 11); err == nil {
+			t.Error("Expected an error for test fraction", f)
+		}
+	}
+}
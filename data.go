@@ -0,0 +1,368 @@
+package libsvm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// DataOption configures how sparse examples are read or built
+type DataOption func(*dataConfig)
+
+// dataConfig holds the settings of LoadProblem and NewSparseExample
+type dataConfig struct {
+	indexBase   int
+	precomputed bool
+}
+
+// WithIndexBase sets the feature index of the first feature in the input,
+// 1 by default as in LIBSVM data files. Data exported with 0-based indices
+// needs WithIndexBase(0); indices are then shifted up by one, so feature 0
+// becomes LIBSVM's index 1. Indices below the base are rejected.
+func WithIndexBase(base int) DataOption {
+	return func(cfg *dataConfig) {
+		cfg.indexBase = base
+	}
+}
+
+// WithPrecomputed reads data for the PRECOMPUTED kernel, in which every line
+// starts with the 0:serial pair the LIBSVM README makes mandatory, giving the
+// example's serial number from 1, followed by its kernel values K(x, x_j) at
+// index j. Only the serial may use index 0; the index base applies to the
+// kernel values.
+func WithPrecomputed() DataOption {
+	return func(cfg *dataConfig) {
+		cfg.precomputed = true
+	}
+}
+
+// newDataConfig applies the options over the defaults
+func newDataConfig(opts []DataOption) dataConfig {
+	cfg := dataConfig{indexBase: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return cfg
+}
+
+// rebase validates the indices against the index base and returns them
+// shifted to LIBSVM's 1-based indices. The input is not modified.
+func (cfg dataConfig) rebase(indices []int) ([]int, error) {
+	if cfg.indexBase == 1 {
+		for i, idx := range indices {
+			if idx == 0 {
+				return nil, SvmError{Message: fmt.Sprintf("invalid feature index 0 at position %d, indices must be positive; use WithIndexBase(0) for 0-based data, or WithPrecomputed for PRECOMPUTED kernel data", i)}
+			}
+		}
+
+		return indices, nil
+	}
+
+	res := make([]int, len(indices))
+	for i, idx := range indices {
+		if idx < cfg.indexBase {
+			return nil, SvmError{Message: fmt.Sprintf("invalid feature index %d at position %d, indices must be at least %d", idx, i, cfg.indexBase)}
+		}
+		res[i] = idx - cfg.indexBase + 1
+	}
+
+	return res, nil
+}
+
+// LoadProblem reads a LIBSVM formatted data file, where each line holds a
+// label followed by sparse index:value pairs, into a problem. Blank lines are
+// skipped. The problem must be released with FreeProblem.
+func LoadProblem(filename string, opts ...DataOption) (*SvmProblem, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, SvmError{Message: fmt.Sprintf("unable to open problem file: %s", filename)}
+	}
+	defer f.Close()
+
+	return readProblem(f, newDataConfig(opts))
+}
+
+// TrainFile trains a model on a LIBSVM formatted data file, like the
+// svm-train program. The model owns the problem loaded from the file, which
+// is released along with it by FreeModel or its finalizer. The file is read
+// WithPrecomputed when param uses the PRECOMPUTED kernel.
+func TrainFile(filename string, param SvmParameter, opts ...DataOption) (*SvmModel, error) {
+	if param.Kernel() == PRECOMPUTED {
+		opts = append([]DataOption{WithPrecomputed()}, opts...)
+	}
+
+	prob, err := LoadProblem(filename, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	mdl, err := Train(*prob, param)
+	if err != nil {
+		FreeProblem(prob)
+		return nil, err
+	}
+
+	mdl.own(prob)
+	return mdl, nil
+}
+
+// readProblem reads LIBSVM formatted lines from r into a problem
+func readProblem(r io.Reader, cfg dataConfig) (*SvmProblem, error) {
+	var labels []float64
+	var indices [][]int
+	var values [][]float64
+
+	scanner := newLineScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		label, idx, vals, ok, err := parseLine(scanner.Text(), cfg)
+		if err != nil {
+			return nil, SvmError{Kind: ErrInvalidInput, Message: fmt.Sprintf("line %d: %s", lineNo, err)}
+		}
+
+		if !ok {
+			continue
+		}
+
+		labels = append(labels, label)
+		indices = append(indices, idx)
+		values = append(values, vals)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, SvmError{Message: fmt.Sprintf("error reading problem data: %s", err)}
+	}
+
+	if len(labels) == 0 {
+		return nil, SvmError{Message: "no examples found in problem data"}
+	}
+
+	return newSparseProblem(labels, indices, values), nil
+}
+
+// WriteTo writes the problem to w in the LIBSVM data format read by
+// LoadProblem and the command line tools, one "label index:value ..." line
+// per example. Every stored feature is written, including any zeros a dense
+// problem holds, and values are formatted so that they read back exactly.
+func (prob *SvmProblem) WriteTo(w io.Writer) (int64, error) {
+	if prob == nil || prob.object == nil {
+		return 0, SvmError{Message: "nil problem when attempting to write an svm problem"}
+	}
+	defer runtime.KeepAlive(prob)
+
+	bw := bufio.NewWriter(w)
+	var total int64
+	labels := problemLabels(*prob)
+	for i, x := range unsafe.Slice(prob.object.x, len(labels)) {
+		line := []byte(strconv.FormatFloat(labels[i], 'g', -1, 64))
+		for _, p := range nodePairs(x) {
+			line = append(line, ' ')
+			line = strconv.AppendInt(line, int64(p.Index), 10)
+			line = append(line, ':')
+			line = strconv.AppendFloat(line, p.Value, 'g', -1, 64)
+		}
+		line = append(line, '\n')
+
+		n, err := bw.Write(line)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, bw.Flush()
+}
+
+// DatasetReader reads LIBSVM formatted examples lazily, one line at a time,
+// so that data sets larger than memory can be streamed.
+type DatasetReader struct {
+	scanner *bufio.Scanner
+	lineNo  int
+}
+
+// NewDatasetReader creates a reader of LIBSVM formatted examples from r
+func NewDatasetReader(r io.Reader) *DatasetReader {
+	return &DatasetReader{scanner: newLineScanner(r)}
+}
+
+// Next returns the label and features of the next example, skipping blank
+// lines. It returns io.EOF once the input is exhausted. Each node is
+// independent of the reader and of other nodes, and should be released with
+// Free when no longer needed.
+func (dr *DatasetReader) Next() (float64, *SvmNode, error) {
+	for dr.scanner.Scan() {
+		dr.lineNo++
+		label, idx, vals, ok, err := parseLine(dr.scanner.Text(), newDataConfig(nil))
+		if err != nil {
+			return 0, nil, SvmError{Kind: ErrInvalidInput, Message: fmt.Sprintf("line %d: %s", dr.lineNo, err)}
+		}
+
+		if !ok {
+			continue
+		}
+
+		node, err := NewSparseExample(idx, vals)
+		if err != nil {
+			return 0, nil, SvmError{Kind: ErrInvalidInput, Message: fmt.Sprintf("line %d: %s", dr.lineNo, err)}
+		}
+
+		return label, node, nil
+	}
+
+	if err := dr.scanner.Err(); err != nil {
+		return 0, nil, SvmError{Message: fmt.Sprintf("error reading data: %s", err)}
+	}
+
+	return 0, nil, io.EOF
+}
+
+// maxStreamErrors is the number of malformed lines PredictStream describes
+// in its error; any further ones are only counted
+const maxStreamErrors = 10
+
+// PredictStream reads LIBSVM formatted examples from r one line at a time and
+// writes each prediction to w on its own line, like the svm-predict program.
+// The label at the start of each input line is ignored. Lines that cannot be
+// parsed produce a "NaN" line, so that output lines stay aligned with the
+// input, and are reported by line number in the error returned once the
+// whole stream has been scored; read, write and prediction failures stop
+// the stream immediately, after writing out the predictions made so far.
+func (mdl *SvmModel) PredictStream(r io.Reader, w io.Writer) (err error) {
+	if err := checkModel(mdl, "predict a stream using an svm model"); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	defer func() {
+		if ferr := bw.Flush(); ferr != nil && err == nil {
+			err = SvmError{Message: fmt.Sprintf("error writing predictions: %s", ferr)}
+		}
+	}()
+	var bad []string
+	nbad := 0
+
+	scanner := newLineScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		_, idx, vals, ok, err := parseLine(scanner.Text(), newDataConfig(nil))
+		if err == nil && !ok {
+			continue
+		}
+
+		var node *SvmNode
+		if err == nil {
+			node, err = NewSparseExample(idx, vals)
+		}
+
+		v := math.NaN()
+		if err != nil {
+			if nbad++; nbad <= maxStreamErrors {
+				bad = append(bad, fmt.Sprintf("line %d: %s", lineNo, err))
+			}
+		} else {
+			v, err = mdl.Predict(node)
+			node.Free()
+			if err != nil {
+				return wrapError(fmt.Sprintf("line %d", lineNo), err)
+			}
+		}
+
+		if _, err := fmt.Fprintf(bw, "%.17g\n", v); err != nil {
+			return SvmError{Message: fmt.Sprintf("error writing predictions: %s", err)}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return SvmError{Message: fmt.Sprintf("error reading prediction data: %s", err)}
+	}
+
+	if nbad > maxStreamErrors {
+		bad = append(bad, fmt.Sprintf("and %d more", nbad-maxStreamErrors))
+	}
+
+	if len(bad) > 0 {
+		return SvmError{Kind: ErrInvalidInput, Message: "unable to parse prediction data: " + strings.Join(bad, "; ")}
+	}
+
+	return nil
+}
+
+// newLineScanner returns a line scanner that tolerates the very long lines
+// found in high dimensional data sets
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<26)
+	return scanner
+}
+
+// parseLine parses a single "label index:value ..." line. ok is false for
+// blank lines, which carry no example.
+func parseLine(line string, cfg dataConfig) (label float64, indices []int, values []float64, ok bool, err error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return 0, nil, nil, false, nil
+	}
+
+	label, err = strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, nil, nil, false, fmt.Errorf("invalid label %q", fields[0])
+	}
+
+	if math.IsNaN(label) || math.IsInf(label, 0) {
+		return 0, nil, nil, false, fmt.Errorf("invalid label %q, labels must be finite", fields[0])
+	}
+
+	indices = make([]int, 0, len(fields)-1)
+	values = make([]float64, 0, len(fields)-1)
+	for _, field := range fields[1:] {
+		sep := strings.IndexByte(field, ':')
+		if sep < 0 {
+			return 0, nil, nil, false, fmt.Errorf("invalid feature %q, expected index:value", field)
+		}
+
+		idx, err := strconv.Atoi(field[:sep])
+		if err != nil {
+			return 0, nil, nil, false, fmt.Errorf("invalid feature index %q", field[:sep])
+		}
+
+		val, err := strconv.ParseFloat(field[sep+1:], 64)
+		if err != nil {
+			return 0, nil, nil, false, fmt.Errorf("invalid feature value %q", field[sep+1:])
+		}
+
+		if err := checkFinite(idx, val); err != nil {
+			return 0, nil, nil, false, err
+		}
+
+		indices = append(indices, idx)
+		values = append(values, val)
+	}
+
+	serial := 0
+	if cfg.precomputed {
+		if len(indices) == 0 || indices[0] != 0 {
+			return 0, nil, nil, false, fmt.Errorf("missing the 0:serial pair required for the PRECOMPUTED kernel")
+		}
+
+		if values[0] < 1 || values[0] != math.Trunc(values[0]) {
+			return 0, nil, nil, false, fmt.Errorf("invalid precomputed sample serial number %g, it must be a whole number of at least 1", values[0])
+		}
+		serial = 1
+	}
+
+	rest, err := cfg.rebase(indices[serial:])
+	if err != nil {
+		return 0, nil, nil, false, err
+	}
+
+	if err := checkIndices(rest); err != nil {
+		return 0, nil, nil, false, err
+	}
+
+	return label, append(indices[:serial], rest...), values, true, nil
+}
@@ -0,0 +1,394 @@
+package libsvm
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestLoadProblem(t *testing.T) {
+	prob, err := LoadProblem("testdata/toy")
+	if err != nil {
+		t.Fatal("LoadProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	if prob.object.l != 5 {
+		t.Error("Expected 5 rows, got", prob.object.l)
+	}
+
+	labels := unsafe.Slice(prob.object.y, 5)
+	if labels[1] != -1 || labels[4] != 2 {
+		t.Error("Labels were not parsed correctly")
+	}
+
+	row := unsafe.Slice(unsafe.Slice(prob.object.x, 5)[1], 3)
+	if row[0].index != 2 || row[1].index != 4 || row[1].value != -0.25 || row[2].index != -1 {
+		t.Error("Row was not parsed correctly")
+	}
+}
+
+func TestIndexBase(t *testing.T) {
+	raw, err := os.ReadFile("testdata/toy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var zeroBased strings.Builder
+	for _, line := range strings.Split(string(raw), "\n") {
+		fields := strings.Fields(line)
+		for i, field := range fields {
+			if i > 0 {
+				parts := strings.SplitN(field, ":", 2)
+				idx, _ := strconv.Atoi(parts[0])
+				field = strconv.Itoa(idx-1) + ":" + parts[1]
+			}
+			zeroBased.WriteString(field + " ")
+		}
+		zeroBased.WriteString("\n")
+	}
+
+	fn := filepath.Join(t.TempDir(), "toy0")
+	if err := os.WriteFile(fn, []byte(zeroBased.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadProblem(fn); err == nil || !strings.Contains(err.Error(), "WithIndexBase(0)") {
+		t.Error("Expected loading 0-based data as 1-based to suggest WithIndexBase, got", err)
+	}
+
+	one, err := LoadProblem("testdata/toy")
+	if err != nil {
+		t.Fatal("LoadProblem error was non-nil", err)
+	}
+	defer FreeProblem(one)
+
+	zero, err := LoadProblem(fn, WithIndexBase(0))
+	if err != nil {
+		t.Fatal("LoadProblem error was non-nil for 0-based data", err)
+	}
+	defer FreeProblem(zero)
+
+	param := NewParameter(C_SVC, LINEAR)
+	defer FreeParam(param)
+
+	want, err := Train(*one, *param)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(want)
+
+	got, err := Train(*zero, *param)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(got)
+
+	if !got.Equal(want) {
+		t.Error("Expected 0-based and 1-based data to train identical models")
+	}
+
+	node, err := NewSparseExample([]int{0, 3}, []float64{1, 1}, WithIndexBase(0))
+	if err != nil {
+		t.Fatal("NewSparseExample error was non-nil", err)
+	}
+	defer node.Free()
+
+	if node.String() != "1:1 4:1" {
+		t.Error("Expected 0-based indices to be shifted, got", node.String())
+	}
+
+	if _, err := NewSparseExample([]int{-1}, []float64{1}, WithIndexBase(0)); err == nil {
+		t.Error("Expected an error for an index below the base")
+	}
+}
+
+func TestLoadPrecomputed(t *testing.T) {
+	if _, err := LoadProblem("testdata/precomputed"); err == nil || !strings.Contains(err.Error(), "WithPrecomputed") {
+		t.Error("Expected loading precomputed data without WithPrecomputed to suggest it, got", err)
+	}
+
+	prob, err := LoadProblem("testdata/precomputed", WithPrecomputed())
+	if err != nil {
+		t.Fatal("LoadProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	label, features, err := prob.Example(2)
+	if err != nil {
+		t.Fatal("Example error was non-nil", err)
+	}
+
+	if prob.object.l != 4 || label != -1 || len(features) != 5 || features[0] != (Pair{0, 3}) || features[4] != (Pair{4, 2}) {
+		t.Errorf("Unexpected third example %v %v", label, features)
+	}
+
+	fn := filepath.Join(t.TempDir(), "noserial")
+	if err := os.WriteFile(fn, []byte("1 1:1 2:2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadProblem(fn, WithPrecomputed()); err == nil || !strings.Contains(err.Error(), "0:serial") {
+		t.Error("Expected an error for a line without a serial number, got", err)
+	}
+}
+
+func TestProblemWriteTo(t *testing.T) {
+	prob, err := LoadProblem("testdata/toy")
+	if err != nil {
+		t.Fatal("LoadProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	fn := filepath.Join(t.TempDir(), "toy")
+	f, err := os.Create(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := prob.WriteTo(f)
+	f.Close()
+	if err != nil {
+		t.Fatal("WriteTo error was non-nil", err)
+	}
+
+	raw, _ := os.ReadFile(fn)
+	if n != int64(len(raw)) || !strings.HasPrefix(string(raw), "1 1:0.5 3:1\n-1 2:1 4:-0.25\n") {
+		t.Errorf("Unexpected output of %d bytes:\n%s", n, raw)
+	}
+
+	reloaded, err := LoadProblem(fn)
+	if err != nil {
+		t.Fatal("LoadProblem error was non-nil for written data", err)
+	}
+	defer FreeProblem(reloaded)
+
+	var again strings.Builder
+	if _, err := reloaded.WriteTo(&again); err != nil {
+		t.Fatal("WriteTo error was non-nil", err)
+	}
+
+	if again.String() != string(raw) || reloaded.object.l != prob.object.l {
+		t.Error("Reloaded problem differs from the original")
+	}
+}
+
+func TestTrainFile(t *testing.T) {
+	param := NewParameter(C_SVC, RBF)
+	defer FreeParam(param)
+	param.SetGamma(1)
+	param.SetC(100)
+
+	mdl, err := TrainFile("testdata/toy", *param)
+	if err != nil {
+		t.Fatal("TrainFile error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	exa, err := NewSparseExample([]int{1, 3}, []float64{0.5, 1})
+	if err != nil {
+		t.Fatal("NewSparseExample error was non-nil", err)
+	}
+	defer exa.Free()
+
+	if label, err := mdl.Predict(exa); err != nil || label != 1 {
+		t.Error("Expected the first training row to be predicted as 1, got", label, err)
+	}
+
+	if _, err := TrainFile("testdata/missing", *param); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestTrainFilePrecomputed(t *testing.T) {
+	param := NewParameter(C_SVC, PRECOMPUTED)
+	defer FreeParam(param)
+
+	mdl, err := TrainFile("testdata/precomputed", *param)
+	if err != nil {
+		t.Fatal("TrainFile error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	// The fixture holds the linear kernel of the points 1, 2, -1 and -2
+	for _, c := range []struct{ x, want float64 }{{-1.5, -1}, {1.5, 1}} {
+		exa, err := NewPrecomputedExample(1, []float64{c.x, 2 * c.x, -c.x, -2 * c.x})
+		if err != nil {
+			t.Fatal("NewPrecomputedExample error was non-nil", err)
+		}
+
+		if v, err := mdl.Predict(exa); err != nil || v != c.want {
+			t.Errorf("Predicted %f for %f, expected %f (%v)", v, c.x, c.want, err)
+		}
+		exa.Free()
+	}
+}
+
+func TestLoadProblemMalformed(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "bad")
+	if err := os.WriteFile(fn, []byte("1 1:1\n-1 a:1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadProblem(fn)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Error("Expected a line numbered error, got", err)
+	}
+
+	for _, line := range []string{"-1 1:nan", "-1 2:1 3:+Inf", "inf 1:1"} {
+		err := os.WriteFile(fn, []byte("1 1:1\n"+line+"\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = LoadProblem(fn)
+		if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("Expected a line numbered ErrInvalidInput error for %q, got %v", line, err)
+		}
+	}
+}
+
+func TestPredictStream(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	raw, err := os.ReadFile("testdata/a1a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(raw), "\n")[:20]
+
+	input := strings.Join(lines[:10], "\n") + "\n-1 x:1\n" + strings.Join(lines[10:], "\n")
+	var out strings.Builder
+	err = mdl.PredictStream(strings.NewReader(input), &out)
+	if err == nil || !strings.Contains(err.Error(), "line 11") {
+		t.Error("Expected a line numbered parse error, got", err)
+	}
+
+	preds := strings.Fields(out.String())
+	if len(preds) != 21 || preds[10] != "NaN" {
+		t.Fatalf("Expected 21 predictions with a NaN placeholder on line 11, got %d: %v", len(preds), preds)
+	}
+	preds = append(preds[:10], preds[11:]...)
+
+	for i, line := range lines {
+		_, idx, vals, _, _ := parseLine(line, newDataConfig(nil))
+		node, _ := NewSparseExample(idx, vals)
+		want, _ := mdl.Predict(node)
+		node.Free()
+
+		got, _ := strconv.ParseFloat(preds[i], 64)
+		if got != want {
+			t.Errorf("Stream prediction %d was %f, expected %f", i, got, want)
+		}
+	}
+
+	out.Reset()
+	err = mdl.PredictStream(strings.NewReader(strings.Repeat("-1 x:1\n", 25)), &out)
+	if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "line 10:") || strings.Contains(err.Error(), "line 11:") || !strings.Contains(err.Error(), "and 15 more") {
+		t.Error("Expected the reported parse errors to be capped, got", err)
+	}
+
+	if n := len(strings.Fields(out.String())); n != 25 {
+		t.Error("Expected 25 placeholder lines, got", n)
+	}
+}
+
+// closingReader returns first, then frees mdl before returning rest
+type closingReader struct {
+	mdl         *SvmModel
+	first, rest string
+	reads       int
+}
+
+func (cr *closingReader) Read(p []byte) (int, error) {
+	cr.reads++
+	switch cr.reads {
+	case 1:
+		return copy(p, cr.first), nil
+	case 2:
+		cr.mdl.Close()
+		return copy(p, cr.rest), nil
+	}
+
+	return 0, io.EOF
+}
+
+func TestPredictStreamFlushesOnError(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	raw, err := os.ReadFile("testdata/a1a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(raw), "\n")
+
+	var out strings.Builder
+	r := &closingReader{mdl: mdl, first: lines[0] + "\n" + lines[1] + "\n", rest: lines[2] + "\n"}
+	err = mdl.PredictStream(r, &out)
+	if !errors.Is(err, ErrFreed) || !strings.Contains(err.Error(), "line 3") {
+		t.Error("Expected an ErrFreed error on line 3, got", err)
+	}
+
+	if n := len(strings.Fields(out.String())); n != 2 {
+		t.Error("Expected the 2 predictions made before the failure to be written, got", n)
+	}
+}
+
+func TestDatasetReader(t *testing.T) {
+	f, err := os.Open("testdata/toy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	dr := NewDatasetReader(f)
+	var labels []float64
+	var prev *SvmNode
+	for {
+		label, node, err := dr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal("Next error was non-nil", err)
+		}
+
+		if prev != nil {
+			prev.Free()
+		}
+		if len(labels) == 1 && node.String() != "2:1 4:-0.25" {
+			t.Errorf("Unexpected second example %q", node.String())
+		}
+
+		labels = append(labels, label)
+		prev = node
+	}
+
+	if len(labels) != 5 || labels[4] != 2 {
+		t.Error("Unexpected labels", labels)
+	}
+
+	if prev.String() != "1:1 4:1" {
+		t.Errorf("Freeing earlier nodes corrupted the last example %q", prev.String())
+	}
+	prev.Free()
+
+	for _, line := range []string{"1 a:1", "1 2:1 1:1", "1 1:inf"} {
+		_, _, err := NewDatasetReader(strings.NewReader(line + "\n")).Next()
+		if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "line 1") {
+			t.Errorf("Expected a line numbered ErrInvalidInput error for %q, got %v", line, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package libsvm
+
+import (
+	"math"
+	"unsafe"
+)
+
+// equalTolerance is the relative difference below which Equal treats two
+// floating point values as the same. Model files store support vector
+// values with 8 significant digits, so a saved and reloaded model only
+// matches its original to about that precision.
+const equalTolerance = 1e-6
+
+// Equal reports whether two models make the same predictions: they must have
+// the same svm and kernel types and kernel parameters, classes, labels, rho,
+// support vectors and coefficients, and probability coefficients, with
+// floating point values compared within a small relative tolerance. The
+// density marks LIBSVM 3.30 and later keep for one-class models trained with
+// probability are not compared. A model saved and reloaded, or cloned, is
+// equal to the original. Two nil or freed models are equal.
+func (mdl *SvmModel) Equal(other *SvmModel) bool {
+	if mdl == other {
+		return true
+	}
+
+	if mdl == nil || other == nil {
+		return mdl.NrClass() == 0 && other.NrClass() == 0
+	}
+
+	// lock in address order, so that a.Equal(b) and b.Equal(a) cannot
+	// deadlock against a Close waiting on either model
+	first, second := mdl, other
+	if uintptr(unsafe.Pointer(second)) < uintptr(unsafe.Pointer(first)) {
+		first, second = second, first
+	}
+	first.mu.RLock()
+	defer first.mu.RUnlock()
+	second.mu.RLock()
+	defer second.mu.RUnlock()
+
+	if mdl.object == nil || other.object == nil {
+		return mdl.object == other.object
+	}
+
+	if mdl.svmType() != other.svmType() || mdl.nrClass() != other.nrClass() || !equalParams(mdl.parameter(), other.parameter()) {
+		return false
+	}
+
+	if !equalInts(mdl.labels(), other.labels()) || !equalInts(mdl.nrSv(), other.nrSv()) || !equalFloats(mdl.rho(), other.rho()) {
+		return false
+	}
+
+	probA, probB, err := mdl.probabilityCoefficients()
+	otherA, otherB, otherErr := other.probabilityCoefficients()
+	if (err == nil) != (otherErr == nil) || !equalFloats(probA, otherA) || !equalFloats(probB, otherB) {
+		return false
+	}
+
+	coefs, otherCoefs := mdl.supportVectorCoefficients(), other.supportVectorCoefficients()
+	if len(coefs) != len(otherCoefs) {
+		return false
+	}
+	for i := range coefs {
+		if !equalFloats(coefs[i], otherCoefs[i]) {
+			return false
+		}
+	}
+
+	svs, otherSvs := mdl.supportVectors(), other.supportVectors()
+	if len(svs) != len(otherSvs) {
+		return false
+	}
+	for i := range svs {
+		if len(svs[i]) != len(otherSvs[i]) {
+			return false
+		}
+		for j, p := range svs[i] {
+			if p.Index != otherSvs[i][j].Index || !closeTo(p.Value, otherSvs[i][j].Value) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// equalParams compares the kernel settings a model uses for prediction
+func equalParams(a, b *SvmParameter) bool {
+	return a.Kernel() == b.Kernel() && a.Degree() == b.Degree() && closeTo(a.Gamma(), b.Gamma()) && closeTo(a.Coef0(), b.Coef0())
+}
+
+// equalInts reports whether two int slices hold the same values
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// equalFloats reports whether two float slices match element-wise within
+// equalTolerance
+func equalFloats(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !closeTo(a[i], b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// closeTo reports whether a and b differ by at most equalTolerance relative
+// to the larger of their magnitudes, or absolutely when both are below 1
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) <= equalTolerance*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
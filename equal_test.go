@@ -0,0 +1,89 @@
+package libsvm
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestModelEqual(t *testing.T) {
+	param := NewParameter(C_SVC, RBF)
+	param.SetGamma(0.5)
+	mdl, prob := trainThreeClass(t, param)
+	defer FreeProblem(prob)
+	defer FreeModel(mdl)
+
+	clone, err := mdl.Clone()
+	if err != nil {
+		t.Fatal("Clone error was non-nil", err)
+	}
+	defer FreeModel(clone)
+
+	if !mdl.Equal(clone) || !clone.Equal(mdl) {
+		t.Error("Expected a model to equal its clone")
+	}
+
+	param = NewParameter(C_SVC, RBF)
+	param.SetGamma(0.5)
+	param.SetC(0.01)
+	other, otherProb := trainThreeClass(t, param)
+	defer FreeProblem(otherProb)
+	defer FreeModel(other)
+
+	if mdl.Equal(other) {
+		t.Error("Expected models trained with different C to differ")
+	}
+
+	param = NewParameter(C_SVC, RBF)
+	param.SetGamma(0.5)
+	param.EnableProbability(true)
+	withProb, withProbProb := trainThreeClass(t, param)
+	defer FreeProblem(withProbProb)
+	defer FreeModel(withProb)
+
+	if mdl.Equal(withProb) || withProb.Equal(mdl) {
+		t.Error("Expected a model trained with probability estimates to differ from one without")
+	}
+
+	if mdl.Equal(nil) {
+		t.Error("Expected a model not to equal nil")
+	}
+}
+
+func TestModelEqualConcurrentClose(t *testing.T) {
+	a, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+	defer FreeModel(a)
+
+	b, err := a.Clone()
+	if err != nil {
+		t.Fatal("Clone error was non-nil", err)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				a.Equal(b)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				b.Equal(a)
+			}
+		}()
+	}
+
+	if err := b.Close(); err != nil {
+		t.Error("Close error was non-nil", err)
+	}
+	wg.Wait()
+
+	if a.Equal(b) {
+		t.Error("Expected a model not to equal a closed one")
+	}
+}
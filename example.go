@@ -0,0 +1,317 @@
+package libsvm
+
+/*
+#include <svm.h>
+#include <stdlib.h>
+
+extern const struct svm_node TERMINATOR;
+*/
+import "C"
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"unsafe"
+)
+
+// NewExample builds a dense example whose features are indexed consecutively
+// from startIndex. The node array is allocated in C memory so that it can be
+// safely handed to LIBSVM; it is released by Free, or by a finalizer if the
+// node becomes unreachable first. Empty data gives a node holding only the
+// terminator, which LIBSVM treats as the all-zero example.
+func NewExample(startIndex int, data []float64) *SvmNode {
+	res := allocNodes(len(data) + 1)
+
+	for i, v := range data {
+		res[i].index = C.int(startIndex + i)
+		res[i].value = C.double(v)
+	}
+
+	res[len(data)] = C.TERMINATOR
+
+	return newNode(res)
+}
+
+// NewDenseExample is like NewExample, but returns an error naming the feature
+// index of the first NaN or infinite value, which LIBSVM would otherwise turn
+// into a meaningless prediction. NewExample does not validate its input.
+func NewDenseExample(startIndex int, data []float64) (*SvmNode, error) {
+	for i, v := range data {
+		if err := checkFinite(startIndex+i, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewExample(startIndex, data), nil
+}
+
+// NewExampleNormalized is like NewExample, but scales the data to unit L2
+// norm first, as cosine similarity style classification expects. An all-zero
+// vector is left unchanged rather than divided by zero. The data slice is not
+// modified.
+func NewExampleNormalized(startIndex int, data []float64) *SvmNode {
+	return NewExample(startIndex, normalized(data))
+}
+
+// NewSparseExampleNormalized is like NewSparseExample, but scales the values
+// to unit L2 norm first. An all-zero vector is left unchanged. The features
+// are validated before scaling, so errors name the offending raw values.
+func NewSparseExampleNormalized(indices []int, values []float64, opts ...DataOption) (*SvmNode, error) {
+	indices, err := checkSparse(indices, values, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return sparseNode(indices, normalized(values)), nil
+}
+
+// normalized returns a copy of values divided by their L2 norm, or values
+// itself when the norm is zero
+func normalized(values []float64) []float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v * v
+	}
+
+	if sum == 0 {
+		return values
+	}
+
+	norm := math.Sqrt(sum)
+	res := make([]float64, len(values))
+	for i, v := range values {
+		res[i] = v / norm
+	}
+
+	return res
+}
+
+// SkipInvalid returns the features with NaN and infinite values removed, for
+// callers that would rather drop such features than reject the example.
+// The result can be passed to NewSparseExample.
+func SkipInvalid(indices []int, values []float64) ([]int, []float64) {
+	var keptIndices []int
+	var keptValues []float64
+	for i, v := range values {
+		if i < len(indices) && !math.IsNaN(v) && !math.IsInf(v, 0) {
+			keptIndices = append(keptIndices, indices[i])
+			keptValues = append(keptValues, v)
+		}
+	}
+
+	return keptIndices, keptValues
+}
+
+// checkFinite ensures a feature value is neither NaN nor infinite
+func checkFinite(index int, value float64) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return SvmError{Kind: ErrInvalidInput, Message: fmt.Sprintf("invalid value %g for feature index %d, values must be finite", value, index)}
+	}
+
+	return nil
+}
+
+// NewSparseExample builds an example holding only the given features.
+// Indices must be positive and strictly increasing, as in LIBSVM data files,
+// unless WithIndexBase says they start elsewhere.
+func NewSparseExample(indices []int, values []float64, opts ...DataOption) (*SvmNode, error) {
+	indices, err := checkSparse(indices, values, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return sparseNode(indices, values), nil
+}
+
+// checkSparse validates the features of a sparse example, returning the
+// indices rebased to start from 1
+func checkSparse(indices []int, values []float64, opts []DataOption) ([]int, error) {
+	if len(indices) != len(values) {
+		return nil, SvmError{Message: fmt.Sprintf("index count %d does not match value count %d", len(indices), len(values))}
+	}
+
+	indices, err := newDataConfig(opts).rebase(indices)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkIndices(indices); err != nil {
+		return nil, err
+	}
+
+	for i, v := range values {
+		if err := checkFinite(indices[i], v); err != nil {
+			return nil, err
+		}
+	}
+
+	return indices, nil
+}
+
+// sparseNode builds the node of a validated sparse example
+func sparseNode(indices []int, values []float64) *SvmNode {
+	res := allocNodes(len(indices) + 1)
+	for i, idx := range indices {
+		res[i].index = C.int(idx)
+		res[i].value = C.double(values[i])
+	}
+
+	res[len(indices)] = C.TERMINATOR
+
+	return newNode(res)
+}
+
+// HashFeatures builds a sparse example from text tokens using the hashing
+// trick: each token is hashed with 32-bit FNV-1a into one of dims feature
+// indices, 1 to dims, and the value of each index is the number of tokens
+// that hashed to it. The hash is fixed, so the same tokens always produce the
+// same example, as training and prediction require.
+func HashFeatures(tokens []string, dims int) (*SvmNode, error) {
+	if dims <= 0 {
+		return nil, SvmError{Message: fmt.Sprintf("invalid number of dimensions %d, must be positive", dims)}
+	}
+
+	counts := make(map[int]float64)
+	for _, tok := range tokens {
+		h := fnv.New32a()
+		h.Write([]byte(tok))
+		counts[int(h.Sum32()%uint32(dims))+1]++
+	}
+
+	indices := make([]int, 0, len(counts))
+	for idx := range counts {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+
+	values := make([]float64, len(indices))
+	for i, idx := range indices {
+		values[i] = counts[idx]
+	}
+
+	return NewSparseExample(indices, values)
+}
+
+// NewPrecomputedExample builds an example for a model using the PRECOMPUTED
+// kernel. LIBSVM expects such examples to hold the sample serial number at
+// index 0, followed by the kernel values K(x, x_j) against every training
+// example j at indices 1..n. The serial number must be at least 1.
+func NewPrecomputedExample(serial int, kernelRow []float64) (*SvmNode, error) {
+	if serial < 1 {
+		return nil, SvmError{Message: fmt.Sprintf("invalid precomputed sample serial number %d, it must be at least 1", serial)}
+	}
+
+	res := allocNodes(len(kernelRow) + 2)
+	res[0].index = 0
+	res[0].value = C.double(serial)
+	for i, v := range kernelRow {
+		res[i+1].index = C.int(i + 1)
+		res[i+1].value = C.double(v)
+	}
+
+	res[len(kernelRow)+1] = C.TERMINATOR
+
+	return newNode(res), nil
+}
+
+// checkIndices ensures feature indices are positive and strictly increasing
+func checkIndices(indices []int) error {
+	for i, idx := range indices {
+		if idx < 1 {
+			return SvmError{Message: fmt.Sprintf("invalid feature index %d at position %d, indices must be positive", idx, i)}
+		}
+
+		if i > 0 && idx <= indices[i-1] {
+			return SvmError{Message: fmt.Sprintf("feature index %d at position %d is not greater than the previous index %d", idx, i, indices[i-1])}
+		}
+	}
+
+	return nil
+}
+
+// allocNodes allocates n zeroed svm_node entries in C memory
+func allocNodes(n int) []C.struct_svm_node {
+	ptr := (*C.struct_svm_node)(C.calloc(C.size_t(n), C.sizeof_struct_svm_node))
+	return unsafe.Slice(ptr, n)
+}
+
+// newNode wraps a C allocated, terminated node array and attaches a finalizer
+// that frees it if the caller never calls Free.
+func newNode(nodes []C.struct_svm_node) *SvmNode {
+	node := &SvmNode{
+		object: &nodes[0],
+		length: len(nodes) - 1,
+	}
+	runtime.SetFinalizer(node, func(n *SvmNode) {
+		C.free(unsafe.Pointer(n.object))
+	})
+
+	return node
+}
+
+// Free will free memory allocated to the node's internal svm_node object(s).
+// Freeing a node a second time does nothing and returns an ErrFreed error.
+// Nodes drawn from a NodePool are returned to it, as by Put.
+func (node *SvmNode) Free() error {
+	if node == nil {
+		return SvmError{Kind: ErrNilNode, Message: "nil node when attempting to free an svm node"}
+	}
+
+	if !atomic.CompareAndSwapInt32(&node.freed, 0, 1) {
+		return SvmError{Kind: ErrFreed, Message: "svm node has already been freed"}
+	}
+
+	if node.buf != nil {
+		node.buf.pool.put(node.buf)
+		node.buf = nil
+	} else {
+		runtime.SetFinalizer(node, nil)
+		C.free(unsafe.Pointer(node.object))
+	}
+	node.length = 0
+	node.object = nil
+	return nil
+}
+
+// Close will free the node's internal svm_node object(s). It is equivalent to Free.
+func (node *SvmNode) Close() error {
+	return node.Free()
+}
+
+// Pairs returns the index/value pairs held by the node, up to the terminator.
+// It returns nil for a nil or freed node.
+func (node *SvmNode) Pairs() []Pair {
+	if node == nil || node.object == nil {
+		return nil
+	}
+
+	pairs := nodePairs(node.object)
+	runtime.KeepAlive(node)
+	return pairs
+}
+
+// String formats the node in LIBSVM data format, e.g. "1:0.5 3:1.2"
+func (node *SvmNode) String() string {
+	pairs := node.Pairs()
+	parts := make([]string, len(pairs))
+	for i, p := range pairs {
+		parts[i] = fmt.Sprintf("%d:%g", p.Index, p.Value)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// nodePairs walks a terminated svm_node array
+func nodePairs(ptr *C.struct_svm_node) []Pair {
+	var pairs []Pair
+	for n := ptr; n.index != -1; n = (*C.struct_svm_node)(unsafe.Add(unsafe.Pointer(n), C.sizeof_struct_svm_node)) {
+		pairs = append(pairs, Pair{Index: int(n.index), Value: float64(n.value)})
+	}
+
+	return pairs
+}
@@ -0,0 +1,204 @@
+package libsvm
+
+import (
+	"errors"
+	"math"
+	"runtime"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestNewExampleFree(t *testing.T) {
+	for i := 0; i < 10000; i++ {
+		exa := NewExample(3, []float64{float64(i), 0, 1})
+		nodes := unsafe.Slice(exa.object, exa.length+1)
+		if nodes[0].index != 3 || nodes[2].index != 5 || float64(nodes[0].value) != float64(i) || nodes[3].index != -1 {
+			t.Fatal("Example nodes were not built correctly")
+		}
+		exa.Free()
+
+		if exa.object != nil || exa.length != 0 {
+			t.Fatal("Free did not clear the node")
+		}
+	}
+
+	for i := 0; i < 1000; i++ {
+		NewExample(1, []float64{1, 2, 3})
+	}
+	runtime.GC()
+}
+
+func TestNewSparseExample(t *testing.T) {
+	exa, err := NewSparseExample([]int{2, 10, 300}, []float64{0.5, 1, -1})
+	if err != nil {
+		t.Fatal("NewSparseExample error was non-nil", err)
+	}
+	defer exa.Free()
+
+	nodes := unsafe.Slice(exa.object, exa.length+1)
+	if exa.length != 3 || nodes[1].index != 10 || nodes[2].index != 300 || nodes[3].index != -1 {
+		t.Error("Sparse example nodes were not built correctly")
+	}
+
+	if _, err := NewSparseExample([]int{3, 2}, []float64{1, 1}); err == nil {
+		t.Error("Expected an error for unsorted indices")
+	}
+
+	if _, err := NewSparseExample([]int{2, 2}, []float64{1, 1}); err == nil {
+		t.Error("Expected an error for duplicate indices")
+	}
+
+	if _, err := NewSparseExample([]int{0}, []float64{1}); err == nil {
+		t.Error("Expected an error for a non-positive index")
+	}
+
+	if _, err := NewSparseExample([]int{1, 2}, []float64{1}); err == nil {
+		t.Error("Expected an error for mismatched lengths")
+	}
+}
+
+func TestNonFiniteFeatures(t *testing.T) {
+	if _, err := NewSparseExample([]int{1, 5}, []float64{1, math.NaN()}); err == nil || !strings.Contains(err.Error(), "index 5") {
+		t.Error("Expected an error naming the NaN feature index, got", err)
+	}
+
+	if _, err := NewDenseExample(1, []float64{0, math.Inf(1)}); err == nil || !strings.Contains(err.Error(), "index 2") {
+		t.Error("Expected an error naming the infinite feature index, got", err)
+	}
+
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	if _, err := mdl.PredictDense([]float64{1, math.Inf(1), 0}); err == nil || !strings.Contains(err.Error(), "index 2") {
+		t.Error("Expected PredictDense to reject an infinite feature, got", err)
+	}
+
+	idx, vals := SkipInvalid([]int{1, 2, 3}, []float64{math.NaN(), 0.5, math.Inf(-1)})
+	if len(idx) != 1 || idx[0] != 2 || vals[0] != 0.5 {
+		t.Error("Expected SkipInvalid to keep only finite features, got", idx, vals)
+	}
+}
+
+func TestNormalizedExamples(t *testing.T) {
+	data := []float64{3, 0, 4}
+	dense := NewExampleNormalized(1, data)
+	defer dense.Free()
+
+	sparse, err := NewSparseExampleNormalized([]int{2, 7}, []float64{-1, 1})
+	if err != nil {
+		t.Fatal("NewSparseExampleNormalized error was non-nil", err)
+	}
+	defer sparse.Free()
+
+	for _, node := range []*SvmNode{dense, sparse} {
+		sum := 0.0
+		for _, p := range node.Pairs() {
+			sum += p.Value * p.Value
+		}
+
+		if math.Abs(sum-1) > 1e-12 {
+			t.Errorf("Expected %s to have unit norm, got a squared norm of %f", node, sum)
+		}
+	}
+
+	if dense.String() != "1:0.6 2:0 3:0.8" || data[0] != 3 {
+		t.Error("Unexpected normalized node, or the input was modified", dense, data)
+	}
+
+	_, err = NewSparseExampleNormalized([]int{1, 4, 6}, []float64{1, math.NaN(), 2})
+	if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "NaN for feature index 4") {
+		t.Error("Expected an error naming the NaN at index 4, got", err)
+	}
+
+	if _, err := NewSparseExampleNormalized([]int{1, 2}, []float64{1, math.Inf(1)}); !errors.Is(err, ErrInvalidInput) {
+		t.Error("Expected an error for an infinite value, got", err)
+	}
+
+	zero := NewExampleNormalized(1, []float64{0, 0})
+	defer zero.Free()
+
+	for _, p := range zero.Pairs() {
+		if p.Value != 0 {
+			t.Error("Expected a zero vector to be left unchanged, got", zero)
+		}
+	}
+}
+
+func TestHashFeatures(t *testing.T) {
+	node, err := HashFeatures([]string{"spam", "ham", "spam", "eggs", "spam"}, 1<<20)
+	if err != nil {
+		t.Fatal("HashFeatures error was non-nil", err)
+	}
+	defer node.Free()
+
+	again, err := HashFeatures([]string{"eggs", "spam", "spam", "ham", "spam"}, 1<<20)
+	if err != nil {
+		t.Fatal("HashFeatures error was non-nil", err)
+	}
+	defer again.Free()
+
+	if node.String() != again.String() {
+		t.Errorf("Expected hashing to be deterministic, got %s and %s", node, again)
+	}
+
+	pairs := node.Pairs()
+	if len(pairs) != 3 {
+		t.Fatal("Expected 3 distinct features, got", pairs)
+	}
+
+	total := 0.0
+	for i, p := range pairs {
+		if p.Index < 1 || p.Index > 1<<20 || (i > 0 && p.Index <= pairs[i-1].Index) {
+			t.Error("Feature indices were not sorted within range", pairs)
+		}
+		total += p.Value
+	}
+
+	if total != 5 {
+		t.Error("Expected counts to total 5, got", total)
+	}
+
+	single, _ := HashFeatures([]string{"spam"}, 1<<20)
+	defer single.Free()
+	for _, p := range pairs {
+		if p.Index == single.Pairs()[0].Index && p.Value != 3 {
+			t.Error("Expected the repeated token to have a count of 3, got", p.Value)
+		}
+	}
+
+	if _, err := HashFeatures([]string{"spam"}, 0); err == nil {
+		t.Error("Expected an error for zero dimensions")
+	}
+}
+
+func TestNodePairs(t *testing.T) {
+	exa, err := NewSparseExample([]int{1, 3, 7}, []float64{0.5, 1.2, -2})
+	if err != nil {
+		t.Fatal("NewSparseExample error was non-nil", err)
+	}
+	defer exa.Free()
+
+	pairs := exa.Pairs()
+	want := []Pair{{1, 0.5}, {3, 1.2}, {7, -2}}
+	if len(pairs) != len(want) {
+		t.Fatal("Expected 3 pairs, got", pairs)
+	}
+
+	for i := range want {
+		if pairs[i] != want[i] {
+			t.Errorf("Pair %d was %v, expected %v", i, pairs[i], want[i])
+		}
+	}
+
+	if exa.String() != "1:0.5 3:1.2 7:-2" {
+		t.Errorf("Unexpected node string %q", exa.String())
+	}
+
+	var empty *SvmNode
+	if empty.Pairs() != nil || empty.String() != "" {
+		t.Error("Expected no pairs from a nil node")
+	}
+}
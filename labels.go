@@ -0,0 +1,69 @@
+package libsvm
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LabelEncoder maps string class labels to the numeric labels LIBSVM works
+// with, and back again. Classes are numbered 0, 1, 2, ... in sorted order,
+// so the mapping only depends on the set of classes seen by Fit.
+type LabelEncoder struct {
+	classes []string
+	index   map[string]float64
+}
+
+// Fit learns the classes in labels and returns their numeric encoding
+func (le *LabelEncoder) Fit(labels []string) []float64 {
+	seen := make(map[string]bool)
+	le.classes = le.classes[:0]
+	for _, l := range labels {
+		if !seen[l] {
+			seen[l] = true
+			le.classes = append(le.classes, l)
+		}
+	}
+	sort.Strings(le.classes)
+
+	le.index = make(map[string]float64, len(le.classes))
+	for i, c := range le.classes {
+		le.index[c] = float64(i)
+	}
+
+	res, _ := le.Transform(labels)
+	return res
+}
+
+// Classes returns the classes learnt by Fit, in encoding order
+func (le *LabelEncoder) Classes() []string {
+	return append([]string(nil), le.classes...)
+}
+
+// Transform encodes labels, returning an error for a class not seen by Fit
+func (le *LabelEncoder) Transform(labels []string) ([]float64, error) {
+	res := make([]float64, len(labels))
+	for i, l := range labels {
+		v, ok := le.index[l]
+		if !ok {
+			return nil, SvmError{Message: fmt.Sprintf("unknown class label %q at index %d", l, i)}
+		}
+		res[i] = v
+	}
+
+	return res, nil
+}
+
+// InverseTransform decodes numeric labels, such as predictions, back to their
+// classes, returning an error for a value that does not encode a class
+func (le *LabelEncoder) InverseTransform(values []float64) ([]string, error) {
+	res := make([]string, len(values))
+	for i, v := range values {
+		idx := int(v)
+		if float64(idx) != v || idx < 0 || idx >= len(le.classes) {
+			return nil, SvmError{Message: fmt.Sprintf("value %g at index %d does not encode a known class", v, i)}
+		}
+		res[i] = le.classes[idx]
+	}
+
+	return res, nil
+}
@@ -0,0 +1,38 @@
+package libsvm
+
+import "testing"
+
+func TestLabelEncoder(t *testing.T) {
+	var le LabelEncoder
+	encoded := le.Fit([]string{"spam", "ham", "eggs", "ham", "spam"})
+
+	want := []float64{2, 1, 0, 1, 2}
+	for i := range want {
+		if encoded[i] != want[i] {
+			t.Errorf("Label %d was encoded as %f, expected %f", i, encoded[i], want[i])
+		}
+	}
+
+	var other LabelEncoder
+	other.Fit([]string{"eggs", "spam", "ham"})
+	if got, _ := other.Transform([]string{"spam"}); got[0] != 2 {
+		t.Error("Expected the encoding to be independent of label order, got", got)
+	}
+
+	decoded, err := le.InverseTransform([]float64{0, 2, 1})
+	if err != nil {
+		t.Fatal("InverseTransform error was non-nil", err)
+	}
+
+	if decoded[0] != "eggs" || decoded[1] != "spam" || decoded[2] != "ham" {
+		t.Error("Unexpected decoded labels", decoded)
+	}
+
+	if _, err := le.Transform([]string{"bacon"}); err == nil {
+		t.Error("Expected an error for an unknown class")
+	}
+
+	if _, err := le.InverseTransform([]float64{3}); err == nil {
+		t.Error("Expected an error for an unknown value")
+	}
+}
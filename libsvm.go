@@ -13,11 +13,31 @@ const struct svm_node TERMINATOR = (struct svm_node) { -1, 0.0 };
 static void model_free(struct svm_model *model) {
 	svm_free_and_destroy_model(&model);
 }
+
+extern void goPrintString(char *);
+
+static void print_go(const char *s) {
+	goPrintString((char *)s);
+}
+
+// libsvm_capture_print routes all LIBSVM output through goPrintString, which
+// remembers it for training errors and then applies the print mode.
+void libsvm_capture_print(void) {
+	svm_set_print_string_function(&print_go);
+}
 */
 import "C"
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -38,116 +58,493 @@ const (
 	PRECOMPUTED = KernelType(C.PRECOMPUTED)
 )
 
-// SvmError wraps LIBSVM failures so they can be handled
+// String returns the name of the svm type, e.g. "C_SVC"
+func (t SvmType) String() string {
+	switch t {
+	case C_SVC:
+		return "C_SVC"
+	case NU_SVC:
+		return "NU_SVC"
+	case ONE_CLASS:
+		return "ONE_CLASS"
+	case EPSILON_SVR:
+		return "EPSILON_SVR"
+	case NU_SVR:
+		return "NU_SVR"
+	}
+
+	return fmt.Sprintf("SvmType(%d)", int(t))
+}
+
+// String returns the name of the kernel type, e.g. "RBF"
+func (k KernelType) String() string {
+	switch k {
+	case LINEAR:
+		return "LINEAR"
+	case POLY:
+		return "POLY"
+	case RBF:
+		return "RBF"
+	case SIGMOID:
+		return "SIGMOID"
+	case PRECOMPUTED:
+		return "PRECOMPUTED"
+	}
+
+	return fmt.Sprintf("KernelType(%d)", int(k))
+}
+
+// Sentinel errors identifying the kind of an SvmError, for use with errors.Is
+var (
+	ErrNilModel         = errors.New("nil svm model")
+	ErrNilNode          = errors.New("nil svm node")
+	ErrInvalidParameter = errors.New("invalid svm parameter")
+	ErrTrainFailed      = errors.New("svm training failed")
+	ErrLoadFailed       = errors.New("svm model load failed")
+	ErrSaveFailed       = errors.New("svm model save failed")
+	ErrNoProbability    = errors.New("svm model has no probability information")
+	ErrFreed            = errors.New("svm object has already been freed")
+	ErrWrongModelType   = errors.New("wrong svm model type")
+	ErrInvalidInput     = errors.New("invalid svm input data")
+)
+
+// SvmError wraps LIBSVM failures so they can be handled.
+// Kind, when set, is one of the sentinel errors above and is matched by errors.Is.
 type SvmError struct {
+	Kind    error
 	Message string
 }
 
-// SvmProblem is a wrapper around the svm_problem struct
+// SvmProblem is a wrapper around the svm_problem struct.
+// All of the C memory backing the problem is owned by the wrapper: the
+// svm_problem itself, the label and row arrays, and one contiguous block of
+// svm_node entries that every row points into.
 type SvmProblem struct {
 	object *C.struct_svm_problem
+	space  *C.struct_svm_node
+	freed  int32
 }
 
 // SvmParameter is a wrapper around the svm_parameter struct
 type SvmParameter struct {
 	object *C.struct_svm_parameter
+	// model is set when the parameter is a view of a model's parameters,
+	// keeping the model alive for as long as the view is in use
+	model *SvmModel
+	freed int32
 }
 
 // SvmModel is a wrapper around the svm_model struct.
 // The intent here is to provide convenience functions in a go-like way
+//
+// Prediction never modifies the underlying svm_model, so a single model may be
+// used by any number of goroutines at once. FreeModel waits for in-flight
+// predictions and accessor calls to finish; predicting after the model is
+// freed returns an error, and the accessors return empty values.
 type SvmModel struct {
 	object *C.struct_svm_model
+	mu     sync.RWMutex
+	// maxIndex is the largest feature index seen in the training data, or in
+	// the support vectors for loaded models
+	maxIndex int
+	freed    int32
+	// handle owns the C memory and carries the finalizer
+	handle *modelHandle
+	// source is the caller's problem retained by TrainRetained
+	source *SvmProblem
+}
+
+// modelHandle owns the memory of a model. It is always allocated by the
+// package, whereas an SvmModel may be decoded into the middle of a larger
+// value, where it cannot carry a finalizer of its own.
+type modelHandle struct {
+	object *C.struct_svm_model
+	// problem is set when the model owns the problem it was trained on,
+	// which is then freed along with the model
+	problem *SvmProblem
+	// param is set when the model owns the parameter it was trained with.
+	// svm_train copies the parameter shallowly, so the model's class weight
+	// arrays are the parameter's and must be freed only along with it.
+	param *SvmParameter
 }
 
 // SvmNode is a wrapper around the svm_node struct.
 type SvmNode struct {
 	object *C.struct_svm_node
 	length int
+	freed  int32
+	// buf is set for nodes drawn from a NodePool, which owns the memory
+	buf *nodeBuffer
+}
+
+// Pair is a single index:value feature of an example
+type Pair struct {
+	Index int
+	Value float64
+}
+
+// libsvmVersion reads the version of the linked LIBSVM; tests replace it to
+// exercise the checks for older versions
+var libsvmVersion = func() int {
+	return int(C.libsvm_version)
 }
 
 // Version will return the libsvm version
 func Version() int {
-	return int(C.libsvm_version)
+	return libsvmVersion()
+}
+
+// Train a model for the given problem using the provided parameters.
+// Will return a model or an error. Classification problems must hold at
+// least two distinct labels; LIBSVM would otherwise silently train a model
+// that predicts the one class for everything.
+func Train(prob SvmProblem, param SvmParameter) (*SvmModel, error) {
+	if err := checkClasses(prob, param); err != nil {
+		return nil, err
+	}
+
+	return trainUnchecked(prob, param)
 }
 
-func NewExample(startIndex int, data []float64) *SvmNode {
-	sidx := startIndex
-	res := make([]C.struct_svm_node, len(data)+1)
+// TrainRetained is like Train, but keeps a reference to prob in the model so
+// that TrainingProblem can map SvIndices back to the original examples. The
+// problem stays reachable, and its memory cannot be reclaimed, for as long as
+// the model is. It must still be freed by the caller, and not before the
+// model, just as for Train.
+func TrainRetained(prob *SvmProblem, param SvmParameter) (*SvmModel, error) {
+	if prob == nil {
+		return nil, SvmError{Message: "nil problem when attempting to train an svm model"}
+	}
 
-	for i, v := range data {
-		res[i].index = C.int(sidx + i)
-		res[i].value = C.double(v)
+	mdl, err := Train(*prob, param)
+	if err != nil {
+		return nil, err
 	}
 
-	res[len(data)].index = -1
-	res[len(data)].value = 0
+	mdl.source = prob
+	return mdl, nil
+}
 
-	return &SvmNode{
-		object: &res[0],
-		length: len(data),
+// TrainSummary describes a freshly trained model
+type TrainSummary struct {
+	NrClass int
+	TotalSv int
+	// NrSv holds the number of support vectors of each class, in the order
+	// of the model's Labels; it is empty for regression and one-class models
+	NrSv []int
+	Rho  []float64
+	// Objective and Iterations hold the final objective value and solver
+	// iteration count of each binary sub-problem, in the order of Rho. They
+	// are left empty in the rare case that the cross validation run for
+	// probability estimates skips folds, since the sub-problems of the final
+	// model can then not be told apart from those of the folds.
+	Objective  []float64
+	Iterations []int
+	// Converged is false if any sub-problem stopped at LIBSVM's iteration
+	// limit rather than reaching the eps tolerance
+	Converged bool
+}
+
+// TrainWithSummary is like Train, but also returns a summary of the model
+// read in one go, for logging or checking the training result. The
+// objective values and convergence are parsed from LIBSVM's output, as in
+// TrainWithProgress, with the same caveats about concurrent trainings.
+func TrainWithSummary(prob SvmProblem, param SvmParameter) (*SvmModel, *TrainSummary, error) {
+	var reports []Progress
+	mdl, err := TrainWithProgress(prob, param, func(p Progress) {
+		if p.Finished {
+			reports = append(reports, p)
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	summary := &TrainSummary{
+		NrClass:   int(mdl.object.nr_class),
+		TotalSv:   int(mdl.object.l),
+		NrSv:      mdl.NrSv(),
+		Rho:       mdl.rho(),
+		Converged: true,
+	}
+
+	final := finalReports(reports, len(summary.Rho), mdl.SvmType(), param.object.probability != 0)
+	if final == nil {
+		final = reports
+	} else {
+		for _, p := range final {
+			summary.Objective = append(summary.Objective, p.Objective)
+			summary.Iterations = append(summary.Iterations, p.Iterations)
+		}
+	}
+
+	for _, p := range final {
+		summary.Converged = summary.Converged && !p.IterationLimit
 	}
+
+	return mdl, summary, nil
 }
 
-// Free will free memory allocated to the node's internal svm_node object(s)
-func (node *SvmNode) Free() {
-	C.free(unsafe.Pointer(node.object))
-	node.length = 0
-	node.object = nil
+// finalReports picks the reports of the n sub-problems of the final model
+// out of every finished report of a training. With probability estimates
+// LIBSVM first cross validates: regression runs five folds before training,
+// while classification runs five folds on each pair of classes before
+// training that pair. nil is returned if the reports do not follow that
+// pattern.
+func finalReports(reports []Progress, n int, svmType SvmType, probability bool) []Progress {
+	if probability && (svmType == C_SVC || svmType == NU_SVC) {
+		const perPair = 6
+		if len(reports) != perPair*n {
+			return nil
+		}
+
+		final := make([]Progress, n)
+		for i := range final {
+			final[i] = reports[perPair*i+perPair-1]
+		}
+		return final
+	}
+
+	if len(reports) < n {
+		return nil
+	}
+
+	return reports[len(reports)-n:]
 }
 
-// Train a model for the given problem using the provided parameters.
-// Will return a model or an error
-func Train(prob SvmProblem, param SvmParameter) (*SvmModel, error) {
+// checkClasses ensures a classification problem has more than one class
+func checkClasses(prob SvmProblem, param SvmParameter) error {
+	if prob.object == nil || param.object == nil {
+		return nil
+	}
+
+	if t := SvmType(param.object.svm_type); t != C_SVC && t != NU_SVC {
+		return nil
+	}
+
+	ys := unsafe.Slice(prob.object.y, int(prob.object.l))
+	if len(ys) == 0 {
+		return nil
+	}
+
+	for _, y := range ys[1:] {
+		if y != ys[0] {
+			return nil
+		}
+	}
+
+	return SvmError{Kind: ErrTrainFailed, Message: fmt.Sprintf("only one class (label %g) in the training data, classification needs at least two", float64(ys[0]))}
+}
+
+// trainUnchecked is Train without the class check, for the folds of a cross
+// validation, which may legitimately hold a single class
+func trainUnchecked(prob SvmProblem, param SvmParameter) (*SvmModel, error) {
+	if err := checkParams(prob, param); err != nil {
+		return nil, err
+	}
+
+	since := printSeq()
 	mdl := C.svm_train(prob.object, param.object)
 	if mdl == nil {
-		return nil, SvmError{Message: "error while training. nil model returned"}
+		return nil, trainFailure(since)
+	}
+
+	model := newModel(mdl)
+	model.maxIndex = maxIndex(prob.object.x, int(prob.object.l))
+	return model, nil
+}
+
+// trainFailure builds the error for svm_train returning no model, giving the
+// last line LIBSVM printed since output sequence number since as the reason
+func trainFailure(since uint64) error {
+	if msg, ok := lastPrint(since); ok {
+		return SvmError{Kind: ErrTrainFailed, Message: "error while training: " + msg}
 	}
 
-	return &SvmModel{object: mdl}, nil
+	return SvmError{Kind: ErrTrainFailed, Message: "error while training. nil model returned"}
+}
+
+// TrainContext is like Train, but returns ctx.Err() if the context is done
+// before training finishes. LIBSVM cannot interrupt a training run, so a
+// cancelled run is abandoned rather than stopped: it keeps running in the
+// background and the resulting model is freed once it completes. Until then
+// the abandoned run still uses prob and param, so they must not be freed.
+func TrainContext(ctx context.Context, prob SvmProblem, param SvmParameter) (*SvmModel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		mdl *SvmModel
+		err error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		mdl, err := Train(prob, param)
+		done <- result{mdl: mdl, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.mdl, res.err
+	case <-ctx.Done():
+		go func() {
+			if res := <-done; res.mdl != nil {
+				FreeModel(res.mdl)
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// CrossValidation splits the problem into nrFold folds, trains on all but one
+// fold and predicts the held out fold in turn. The returned slice holds the
+// predicted label (or value, for regression) of every example in the problem,
+// in problem order.
+func CrossValidation(prob SvmProblem, param SvmParameter, nrFold int) ([]float64, error) {
+	if nrFold < 2 {
+		return nil, SvmError{Message: fmt.Sprintf("invalid number of folds %d, at least 2 are required for cross validation", nrFold)}
+	}
+
+	if err := checkParams(prob, param); err != nil {
+		return nil, err
+	}
+
+	target := make([]C.double, int(prob.object.l))
+	C.svm_cross_validation(prob.object, param.object, C.int(nrFold), &target[0])
+
+	res := make([]float64, len(target))
+	for i, v := range target {
+		res[i] = float64(v)
+	}
+
+	return res, nil
 }
 
 // Load a model from disk. This will return an error message if
-// there is a problem loading from disk.
+// there is a problem loading from disk. A warning is printed, as LIBSVM's own
+// output is, when the file looks newer than the linked LIBSVM.
 func Load(filename string) (*SvmModel, error) {
 
+	if err := checkModelHeader(filename); err != nil {
+		return nil, err
+	}
+
+	checkModelVersion(filename)
+
 	cfn := C.CString(filename)
 	defer C.free(unsafe.Pointer(cfn))
 
 	mdl := C.svm_load_model(cfn)
 	if mdl == nil {
-		return nil, SvmError{Message: fmt.Sprintf("unable to load model file: %s", filename)}
+		return nil, SvmError{Kind: ErrLoadFailed, Message: fmt.Sprintf("unable to load model file: %s", filename)}
 	}
 
-	return &SvmModel{object: mdl}, nil
+	model := newModel(mdl)
+	model.maxIndex = maxIndex(mdl.SV, int(mdl.l))
+	return model, nil
 }
 
-// FreeModel will free the underlying svm_model structure
-func FreeModel(mdl *SvmModel) error {
+// free releases the svm_model and clears its finalizer. The caller must
+// hold the write lock, or otherwise have exclusive access.
+func (mdl *SvmModel) free() {
+	if mdl.handle != nil {
+		runtime.SetFinalizer(mdl.handle, nil)
+		mdl.handle.release()
+	}
+	mdl.handle = nil
+	mdl.object = nil
+	mdl.source = nil
+}
 
-	if mdl == nil {
-		return SvmError{Message: "nil model when attempting to free an svm model"}
+// release frees the svm_model and any problem the model owns
+func (h *modelHandle) release() {
+	C.model_free(h.object)
+	h.object = nil
+	if h.problem != nil {
+		h.problem.Close()
+		h.problem = nil
+	}
+	if h.param != nil {
+		h.param.Close()
+		h.param = nil
 	}
+}
 
-	if mdl.object == nil {
-		return SvmError{Message: "model object's internal svm_model pointer is nil when attempting to free an svm model"}
+// own hands prob to the model, to be freed along with it
+func (mdl *SvmModel) own(prob *SvmProblem) {
+	mdl.handle.problem = prob
+}
+
+// ownParam hands the parameter the model was trained with to the model, to
+// be freed along with it
+func (mdl *SvmModel) ownParam(param *SvmParameter) {
+	mdl.handle.param = param
+}
+
+// checkModelHeader reads the first line of a model file and ensures it starts
+// with the svm_type header that every LIBSVM model file begins with, so that
+// empty or unrelated files are reported clearly rather than as a generic
+// load failure
+func checkModelHeader(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return SvmError{Kind: ErrLoadFailed, Message: fmt.Sprintf("unable to load model file: %s", filename)}
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if len(line) == 0 && err != nil {
+		return SvmError{Kind: ErrLoadFailed, Message: fmt.Sprintf("not a LIBSVM model file: %s is empty", filename)}
+	}
+
+	if fields := strings.Fields(line); len(fields) == 0 || fields[0] != "svm_type" {
+		return SvmError{Kind: ErrLoadFailed, Message: fmt.Sprintf("not a LIBSVM model file: missing svm_type header in %s", filename)}
 	}
 
-	C.model_free(mdl.object)
 	return nil
 }
 
-// FreeParam will free the underlying svm_parameter structure
-func FreeParam(param *SvmParameter) error {
+// newModel wraps an svm_model and attaches a finalizer that frees it if the
+// caller never calls FreeModel. Explicitly freeing models is still preferred,
+// since it releases the C memory deterministically; the finalizer is only a
+// safety net.
+func newModel(obj *C.struct_svm_model) *SvmModel {
+	h := &modelHandle{object: obj}
+	runtime.SetFinalizer(h, (*modelHandle).release)
 
-	if param == nil {
-		return &SvmError{Message: "nil param when attempting to free an svm parameter"}
+	return &SvmModel{object: obj, handle: h}
+}
+
+// FreeModel will free the underlying svm_model structure.
+// Models returned by Train and Load are also freed by a finalizer once they
+// become unreachable, but calling FreeModel releases the memory immediately.
+// It is equivalent to mdl.Close().
+func FreeModel(mdl *SvmModel) error {
+	return mdl.Close()
+}
+
+// Close will free the underlying svm_model structure
+func (mdl *SvmModel) Close() error {
+
+	if mdl == nil {
+		return SvmError{Kind: ErrNilModel, Message: "nil model when attempting to free an svm model"}
+	}
+
+	mdl.mu.Lock()
+	defer mdl.mu.Unlock()
+
+	if atomic.LoadInt32(&mdl.freed) != 0 {
+		return SvmError{Kind: ErrFreed, Message: "svm model has already been freed"}
 	}
 
-	if param.object == nil {
-		return &SvmError{Message: "param object's internal svm_parameter pointer is nil when attempting to free an svm parameter"}
+	if mdl.object == nil {
+		return SvmError{Kind: ErrNilModel, Message: "model object's internal svm_model pointer is nil when attempting to free an svm model"}
 	}
 
-	C.svm_destroy_param(param.object)
+	atomic.StoreInt32(&mdl.freed, 1)
+	mdl.free()
 	return nil
 }
 
@@ -155,19 +552,20 @@ func FreeParam(param *SvmParameter) error {
 // This will return a generic error message if it is unable to save to disk
 func (mdl *SvmModel) Save(filename string) error {
 	if mdl == nil {
-		return SvmError{Message: "nil model when attempting to save an svm model"}
+		return SvmError{Kind: ErrNilModel, Message: "nil model when attempting to save an svm model"}
 	}
 
 	if mdl.object == nil {
-		return SvmError{Message: "model object's internal svm_model pointer is nil when attempting to save an svm model"}
+		return SvmError{Kind: ErrNilModel, Message: "model object's internal svm_model pointer is nil when attempting to save an svm model"}
 	}
 
 	cfn := C.CString(filename)
 	defer C.free(unsafe.Pointer(cfn))
 
 	cerr := C.svm_save_model(cfn, mdl.object)
+	runtime.KeepAlive(mdl)
 	if cerr != 0 {
-		return SvmError{Message: fmt.Sprintf("unable to save model to file: %s", filename)}
+		return SvmError{Kind: ErrSaveFailed, Message: fmt.Sprintf("unable to save model to file: %s", filename)}
 	}
 
 	return nil
@@ -178,27 +576,421 @@ func (err SvmError) Error() string {
 	return err.Message
 }
 
-// Predict will use the model to predict the next values based on the inputs in the SvmNode object
-func (mdl *SvmModel) Predict(node *SvmNode) (float64, error) {
+// Unwrap returns the kind of the error, so that errors.Is can match it
+func (err SvmError) Unwrap() error {
+	return err.Kind
+}
+
+// wrapError prefixes the message of err with context, keeping its kind
+func wrapError(context string, err error) error {
+	var svmErr SvmError
+	if errors.As(err, &svmErr) {
+		return SvmError{Kind: svmErr.Kind, Message: context + ": " + svmErr.Message}
+	}
+
+	return SvmError{Message: context + ": " + err.Error()}
+}
+
+// NrClass returns the number of classes in the model. It is 2 for regression
+// and one-class models, and 0 for a nil model.
+func (mdl *SvmModel) NrClass() int {
 	if mdl == nil {
-		return -1, SvmError{Message: "nil model when attempting to predict using an svm model"}
+		return 0
 	}
 
+	mdl.mu.RLock()
+	defer mdl.mu.RUnlock()
+	return mdl.nrClass()
+}
+
+// nrClass is NrClass for callers holding the lock
+func (mdl *SvmModel) nrClass() int {
 	if mdl.object == nil {
-		return -1, SvmError{Message: "model object's internal svm_model pointer is nil when attempting to predict using an svm model"}
+		return 0
+	}
+
+	defer runtime.KeepAlive(mdl)
+	return int(C.svm_get_nr_class(mdl.object))
+}
+
+// SvmType returns the type of svm the model was trained as
+func (mdl *SvmModel) SvmType() SvmType {
+	if mdl == nil {
+		return 0
 	}
 
-	if node == nil {
-		return -1, SvmError{Message: "nil node when attempting to predict using an svm model"}
+	mdl.mu.RLock()
+	defer mdl.mu.RUnlock()
+	return mdl.svmType()
+}
+
+// svmType is SvmType for callers holding the lock
+func (mdl *SvmModel) svmType() SvmType {
+	if mdl.object == nil {
+		return 0
 	}
 
-	if node.object == nil {
-		return -1, SvmError{Message: "node object's internal svm_node pointer is nil when attempting to predict using an svm model"}
+	defer runtime.KeepAlive(mdl)
+	return SvmType(C.svm_get_svm_type(mdl.object))
+}
+
+// Labels returns the class labels of a classification model in the order
+// LIBSVM uses internally. It returns nil for regression and one-class models.
+func (mdl *SvmModel) Labels() []int {
+	if mdl == nil {
+		return nil
 	}
 
-	return float64(C.svm_predict(mdl.object, node.object)), nil
+	mdl.mu.RLock()
+	defer mdl.mu.RUnlock()
+	return mdl.labels()
 }
 
-func (mdl *SvmModel) PredictValues() []float64 {
-	return nil
+// labels is Labels for callers holding the lock
+func (mdl *SvmModel) labels() []int {
+	if mdl.object == nil || mdl.object.label == nil {
+		return nil
+	}
+
+	buf := make([]C.int, int(C.svm_get_nr_class(mdl.object)))
+	C.svm_get_labels(mdl.object, &buf[0])
+	runtime.KeepAlive(mdl)
+
+	labels := make([]int, len(buf))
+	for i, v := range buf {
+		labels[i] = int(v)
+	}
+
+	return labels
+}
+
+// ProbabilityLabels returns the labels that the probabilities returned by
+// PredictProbability belong to, index for index. LIBSVM orders classes by
+// their first appearance in the training data rather than numerically, for
+// C_SVC and NU_SVC alike, so probs[i] is the probability of labels[i] and
+// not of the i-th smallest label. An error is returned for models without
+// probability estimates.
+func (mdl *SvmModel) ProbabilityLabels() ([]int, error) {
+	if mdl != nil {
+		mdl.mu.RLock()
+		defer mdl.mu.RUnlock()
+	}
+
+	if err := checkModel(mdl, "get the probability labels of an svm model"); err != nil {
+		return nil, err
+	}
+
+	if !mdl.supportsProbability() || mdl.object.label == nil {
+		return nil, SvmError{Kind: ErrNoProbability, Message: "model does not contain probability estimates for classes when attempting to get the probability labels"}
+	}
+
+	return mdl.labels(), nil
+}
+
+// TrainingProblem returns the problem the model was trained on, when the
+// model was trained by TrainRetained or owns its problem, as for TrainFile.
+// It returns nil otherwise, including for loaded models.
+func (mdl *SvmModel) TrainingProblem() *SvmProblem {
+	if mdl == nil {
+		return nil
+	}
+
+	if mdl.source != nil {
+		return mdl.source
+	}
+
+	if mdl.handle == nil {
+		return nil
+	}
+
+	return mdl.handle.problem
+}
+
+// Parameter returns a view of the parameters the model was trained with.
+// The view aliases the model's own memory: it cannot be freed, its setters
+// return an error, and only the fields LIBSVM stores in model files are
+// populated for loaded models. It returns nil for a nil model.
+func (mdl *SvmModel) Parameter() *SvmParameter {
+	if mdl == nil {
+		return nil
+	}
+
+	mdl.mu.RLock()
+	defer mdl.mu.RUnlock()
+	return mdl.parameter()
+}
+
+// parameter is Parameter for callers holding the lock
+func (mdl *SvmModel) parameter() *SvmParameter {
+	if mdl.object == nil {
+		return nil
+	}
+
+	return &SvmParameter{object: &mdl.object.param, model: mdl}
+}
+
+// TotalSv returns the total number of support vectors in the model
+func (mdl *SvmModel) TotalSv() int {
+	if mdl == nil {
+		return 0
+	}
+
+	mdl.mu.RLock()
+	defer mdl.mu.RUnlock()
+	if mdl.object == nil {
+		return 0
+	}
+
+	defer runtime.KeepAlive(mdl)
+	return int(C.svm_get_nr_sv(mdl.object))
+}
+
+// NrSv returns the number of support vectors for each class of a
+// classification model, ordered to match Labels. It returns an empty slice for
+// regression and one-class models.
+func (mdl *SvmModel) NrSv() []int {
+	if mdl == nil {
+		return []int{}
+	}
+
+	mdl.mu.RLock()
+	defer mdl.mu.RUnlock()
+	return mdl.nrSv()
+}
+
+// nrSv is NrSv for callers holding the lock
+func (mdl *SvmModel) nrSv() []int {
+	if mdl.object == nil || mdl.object.nSV == nil {
+		return []int{}
+	}
+
+	defer runtime.KeepAlive(mdl)
+	nSV := unsafe.Slice(mdl.object.nSV, int(mdl.object.nr_class))
+	res := make([]int, len(nSV))
+	for i, v := range nSV {
+		res[i] = int(v)
+	}
+
+	return res
+}
+
+// SvIndices returns the 1-based indices, into the training problem, of the
+// examples that became support vectors. LIBSVM only records these for models
+// produced by Train, so loaded models return an empty slice.
+func (mdl *SvmModel) SvIndices() []int {
+	if mdl == nil {
+		return []int{}
+	}
+
+	mdl.mu.RLock()
+	defer mdl.mu.RUnlock()
+	if mdl.object == nil || mdl.object.sv_indices == nil {
+		return []int{}
+	}
+
+	total := int(C.svm_get_nr_sv(mdl.object))
+	if total == 0 {
+		return []int{}
+	}
+
+	buf := make([]C.int, total)
+	C.svm_get_sv_indices(mdl.object, &buf[0])
+	runtime.KeepAlive(mdl)
+
+	res := make([]int, len(buf))
+	for i, v := range buf {
+		res[i] = int(v)
+	}
+
+	return res
+}
+
+// Rho returns the bias terms of the model's decision functions: one per pair
+// of classes for classification models, in the same order as the decision
+// values returned by PredictValues, or a single value for regression and
+// one-class models. It returns an empty slice for a nil model.
+func (mdl *SvmModel) Rho() []float64 {
+	if mdl == nil {
+		return []float64{}
+	}
+
+	mdl.mu.RLock()
+	defer mdl.mu.RUnlock()
+	if mdl.object == nil {
+		return []float64{}
+	}
+
+	return mdl.rho()
+}
+
+// rho returns a copy of the model's nr_class*(nr_class-1)/2 bias terms. The
+// caller must hold the lock.
+func (mdl *SvmModel) rho() []float64 {
+	nrClass := int(mdl.object.nr_class)
+	vals := unsafe.Slice(mdl.object.rho, nrClass*(nrClass-1)/2)
+	res := make([]float64, len(vals))
+	for i, v := range vals {
+		res[i] = float64(v)
+	}
+
+	runtime.KeepAlive(mdl)
+	return res
+}
+
+// SupportsProbability reports whether the model was trained with probability
+// estimates, and so can be used with PredictProbability. It is false for a nil model.
+func (mdl *SvmModel) SupportsProbability() bool {
+	if mdl == nil {
+		return false
+	}
+
+	mdl.mu.RLock()
+	defer mdl.mu.RUnlock()
+	return mdl.supportsProbability()
+}
+
+// supportsProbability is SupportsProbability for callers holding the lock
+func (mdl *SvmModel) supportsProbability() bool {
+	if mdl.object == nil {
+		return false
+	}
+
+	defer runtime.KeepAlive(mdl)
+	return C.svm_check_probability_model(mdl.object) != 0
+}
+
+// SupportVectorCoefficients returns the (nr_class-1) x l matrix of support
+// vector coefficients, where l is the total number of support vectors.
+// Row j, column i is the coefficient of support vector i in the decision
+// functions against class j, following LIBSVM's sv_coef layout.
+// It returns nil for a nil model.
+func (mdl *SvmModel) SupportVectorCoefficients() [][]float64 {
+	if mdl == nil {
+		return nil
+	}
+
+	mdl.mu.RLock()
+	defer mdl.mu.RUnlock()
+	return mdl.supportVectorCoefficients()
+}
+
+// supportVectorCoefficients is SupportVectorCoefficients for callers holding
+// the lock
+func (mdl *SvmModel) supportVectorCoefficients() [][]float64 {
+	if mdl.object == nil || mdl.object.sv_coef == nil {
+		return nil
+	}
+	defer runtime.KeepAlive(mdl)
+
+	l := int(mdl.object.l)
+	rows := unsafe.Slice(mdl.object.sv_coef, int(mdl.object.nr_class)-1)
+	res := make([][]float64, len(rows))
+	for j, row := range rows {
+		res[j] = make([]float64, l)
+		for i, v := range unsafe.Slice(row, l) {
+			res[j][i] = float64(v)
+		}
+	}
+
+	return res
+}
+
+// SupportVectors returns the feature vectors of the model's support vectors,
+// ordered by class as in NrSv. It returns nil for a nil model.
+func (mdl *SvmModel) SupportVectors() [][]Pair {
+	if mdl == nil {
+		return nil
+	}
+
+	mdl.mu.RLock()
+	defer mdl.mu.RUnlock()
+	return mdl.supportVectors()
+}
+
+// supportVectors is SupportVectors for callers holding the lock
+func (mdl *SvmModel) supportVectors() [][]Pair {
+	if mdl.object == nil || mdl.object.SV == nil {
+		return nil
+	}
+	defer runtime.KeepAlive(mdl)
+
+	svs := unsafe.Slice(mdl.object.SV, int(mdl.object.l))
+	res := make([][]Pair, len(svs))
+	for i, sv := range svs {
+		res[i] = nodePairs(sv)
+	}
+
+	return res
+}
+
+// SvrProbability returns the sigma of the Laplace distribution LIBSVM fits to
+// the residuals of a regression model trained with probability estimates,
+// which can be used to build confidence intervals around predictions.
+// An error is returned, along with 0, when the model is not such a model;
+// 0 always means no probability information was stored.
+func (mdl *SvmModel) SvrProbability() (float64, error) {
+	if mdl != nil {
+		mdl.mu.RLock()
+		defer mdl.mu.RUnlock()
+	}
+
+	if err := checkModel(mdl, "get the svr probability of an svm model"); err != nil {
+		return 0, err
+	}
+	defer runtime.KeepAlive(mdl)
+
+	svmType := SvmType(C.svm_get_svm_type(mdl.object))
+	if (svmType != EPSILON_SVR && svmType != NU_SVR) || C.svm_check_probability_model(mdl.object) == 0 {
+		return 0, SvmError{Kind: ErrNoProbability, Message: fmt.Sprintf("%s model does not contain svr probability information", svmType)}
+	}
+
+	return float64(C.svm_get_svr_probability(mdl.object)), nil
+}
+
+// ProbabilityCoefficients returns copies of the A and B parameters of the
+// sigmoids 1/(1+exp(A*f+B)) LIBSVM fits to each pairwise decision value f of
+// a classification model trained with probability estimates. Both hold one
+// entry per pair of classes, in the same order as Rho. For regression models
+// probA holds only the Laplace sigma returned by SvrProbability and probB is
+// nil. An error is returned for models without probability information.
+func (mdl *SvmModel) ProbabilityCoefficients() (probA, probB []float64, err error) {
+	if mdl != nil {
+		mdl.mu.RLock()
+		defer mdl.mu.RUnlock()
+	}
+
+	return mdl.probabilityCoefficients()
+}
+
+// probabilityCoefficients is ProbabilityCoefficients for callers holding the
+// lock
+func (mdl *SvmModel) probabilityCoefficients() (probA, probB []float64, err error) {
+	if err := checkModel(mdl, "get the probability coefficients of an svm model"); err != nil {
+		return nil, nil, err
+	}
+	defer runtime.KeepAlive(mdl)
+
+	if C.svm_check_probability_model(mdl.object) == 0 || mdl.object.probA == nil {
+		return nil, nil, SvmError{Kind: ErrNoProbability, Message: "model does not contain probability estimates when attempting to get the probability coefficients"}
+	}
+
+	n := 1
+	if svmType := SvmType(mdl.object.param.svm_type); svmType == C_SVC || svmType == NU_SVC {
+		nrClass := int(mdl.object.nr_class)
+		n = nrClass * (nrClass - 1) / 2
+	}
+
+	probA = make([]float64, n)
+	for i, v := range unsafe.Slice(mdl.object.probA, n) {
+		probA[i] = float64(v)
+	}
+
+	if mdl.object.probB != nil {
+		probB = make([]float64, n)
+		for i, v := range unsafe.Slice(mdl.object.probB, n) {
+			probB[i] = float64(v)
+		}
+	}
+
+	return probA, probB, nil
 }
@@ -1,14 +1,185 @@
 package libsvm
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 )
 
 func TestTrain(t *testing.T) {
 }
 
+func TestTrainContext(t *testing.T) {
+	prob, err := NewProblem([]float64{1, -1}, [][]float64{{1}, {-1}})
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	param := NewParameter(C_SVC, LINEAR)
+	defer FreeParam(param)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mdl, err := TrainContext(ctx, *prob, *param)
+	if err != context.Canceled || mdl != nil {
+		t.Error("Expected a context cancellation error, got", err)
+	}
+
+	mdl, err = TrainContext(context.Background(), *prob, *param)
+	if err != nil || mdl == nil {
+		t.Fatal("TrainContext error was non-nil", err)
+	}
+	FreeModel(mdl)
+}
+
+func TestTypeStrings(t *testing.T) {
+	cases := []struct {
+		value fmt.Stringer
+		want  string
+	}{
+		{C_SVC, "C_SVC"},
+		{NU_SVC, "NU_SVC"},
+		{ONE_CLASS, "ONE_CLASS"},
+		{EPSILON_SVR, "EPSILON_SVR"},
+		{NU_SVR, "NU_SVR"},
+		{SvmType(42), "SvmType(42)"},
+		{LINEAR, "LINEAR"},
+		{POLY, "POLY"},
+		{RBF, "RBF"},
+		{SIGMOID, "SIGMOID"},
+		{PRECOMPUTED, "PRECOMPUTED"},
+		{KernelType(-1), "KernelType(-1)"},
+	}
+
+	for _, c := range cases {
+		if got := c.value.String(); got != c.want {
+			t.Errorf("Expected %q, got %q", c.want, got)
+		}
+	}
+}
+
+func TestTrainWithSummary(t *testing.T) {
+	labels, X := threeClassData()
+	prob, err := NewProblem(labels, X)
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	param := NewParameter(C_SVC, LINEAR)
+	defer FreeParam(param)
+
+	mdl, summary, err := TrainWithSummary(*prob, *param)
+	if err != nil {
+		t.Fatal("TrainWithSummary error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	if summary.NrClass != 3 || len(summary.NrSv) != 3 || len(summary.Rho) != 3 {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+
+	total := 0
+	for _, n := range summary.NrSv {
+		total += n
+	}
+
+	if summary.TotalSv != len(mdl.SvIndices()) || total != summary.TotalSv {
+		t.Errorf("Summary counts %d support vectors, per class %v, the model has %d", summary.TotalSv, summary.NrSv, len(mdl.SvIndices()))
+	}
+	if len(summary.Objective) != 3 || len(summary.Iterations) != 3 || !summary.Converged {
+		t.Errorf("Expected converged objectives for 3 sub-problems, got %+v", summary)
+	}
+
+	// probability estimates cross validate each pair before training it
+	param.EnableProbability(true)
+	probMdl, probSummary, err := TrainWithSummary(*prob, *param)
+	if err != nil {
+		t.Fatal("TrainWithSummary error was non-nil", err)
+	}
+	defer FreeModel(probMdl)
+
+	if len(probSummary.Objective) != len(probSummary.Rho) || len(probSummary.Iterations) != len(probSummary.Rho) {
+		t.Fatalf("Expected one objective per rho, got %+v", probSummary)
+	}
+
+	for i, obj := range probSummary.Objective {
+		if math.Abs(obj-summary.Objective[i]) > 1e-3*math.Abs(summary.Objective[i])+1e-6 {
+			t.Errorf("Sub-problem %d objective %f does not match %f from training without probability", i, obj, summary.Objective[i])
+		}
+	}
+
+	reports := []Progress{{Objective: 1}, {Objective: 2}, {Objective: 3}}
+	if final := finalReports(reports, 1, EPSILON_SVR, true); len(final) != 1 || final[0].Objective != 3 {
+		t.Error("Expected the last report for a regression model, got", final)
+	}
+
+	if final := finalReports(reports, 1, C_SVC, true); final != nil {
+		t.Error("Expected no reports when the cross validation pattern does not match, got", final)
+	}
+}
+
+func TestTrainSingleClass(t *testing.T) {
+	prob, err := NewProblem([]float64{1, 1, 1}, [][]float64{{1, 0}, {0, 1}, {1, 1}})
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	param := NewParameter(C_SVC, LINEAR)
+	defer FreeParam(param)
+
+	if _, err := Train(*prob, *param); !errors.Is(err, ErrTrainFailed) || !strings.Contains(err.Error(), "only one class") {
+		t.Error("Expected a single class error, got", err)
+	}
+
+	oneClass := NewParameter(ONE_CLASS, LINEAR)
+	defer FreeParam(oneClass)
+
+	mdl, err := Train(*prob, *oneClass)
+	if err != nil {
+		t.Fatal("Expected ONE_CLASS training to accept a single label, got", err)
+	}
+	FreeModel(mdl)
+}
+
+func TestCrossValidation(t *testing.T) {
+	labels, examples := readDenseData(t, "testdata/a1a", 123)
+	prob, err := NewProblem(labels, examples)
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	param := NewParameter(C_SVC, RBF)
+	defer FreeParam(param)
+	param.SetGamma(1.0 / 123)
+
+	target, err := CrossValidation(*prob, *param, 5)
+	if err != nil {
+		t.Fatal("CrossValidation error was non-nil", err)
+	}
+
+	if len(target) != len(labels) {
+		t.Error("Expected one prediction per example, got", len(target))
+	}
+
+	if _, err := CrossValidation(*prob, *param, 1); err == nil {
+		t.Error("Expected an error for fewer than 2 folds")
+	}
+}
+
 func TestSimpleLoad(t *testing.T) {
 	mdl, err := Load("testdata/a1a.model")
 	if err != nil {
@@ -20,6 +191,473 @@ func TestSimpleLoad(t *testing.T) {
 	}
 }
 
+func TestModelIntrospection(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	if mdl.NrClass() != 2 {
+		t.Error("Expected 2 classes, got", mdl.NrClass())
+	}
+
+	if mdl.SvmType() != C_SVC {
+		t.Error("Expected a C_SVC model, got", mdl.SvmType())
+	}
+
+	labels := mdl.Labels()
+	if len(labels) != 2 || labels[0] != 1 || labels[1] != -1 {
+		t.Error("Expected labels [1 -1], got", labels)
+	}
+
+	var empty *SvmModel
+	if empty.NrClass() != 0 || empty.Labels() != nil {
+		t.Error("Expected zero values from a nil model")
+	}
+}
+
+func TestConcurrentGettersAndClose(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	node := NewExample(1, []float64{1})
+	defer node.Free()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				// a freed model reads as empty rather than crashing
+				if labels := mdl.Labels(); labels != nil && len(labels) != 2 {
+					t.Error("Unexpected labels", labels)
+					return
+				}
+				mdl.NrSv()
+				mdl.Rho()
+				mdl.SupportVectors()
+				mdl.SupportVectorCoefficients()
+				mdl.SvIndices()
+				mdl.ProbabilityCoefficients()
+				mdl.PredictStrict(node)
+				mdl.PredictVotes(node)
+			}
+		}()
+	}
+
+	if err := mdl.Close(); err != nil {
+		t.Error("Close error was non-nil", err)
+	}
+	wg.Wait()
+
+	if mdl.Labels() != nil || len(mdl.Rho()) != 0 {
+		t.Error("Expected a closed model to have no labels or rho")
+	}
+}
+
+func TestModelFinalizer(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		if _, err := Load("testdata/a1a.model"); err != nil {
+			t.Fatal("Model load error was non-nil", err)
+		}
+	}
+
+	runtime.GC()
+	runtime.GC()
+
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	if err := FreeModel(mdl); err != nil {
+		t.Error("FreeModel error was non-nil", err)
+	}
+
+	runtime.GC()
+}
+
+func TestSupportVectors(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	nsv := mdl.NrSv()
+	if len(nsv) != 2 || nsv[0] != 371 || nsv[1] != 383 || mdl.TotalSv() != 754 {
+		t.Error("Unexpected support vector counts", nsv, mdl.TotalSv())
+	}
+
+	labels, examples := readDenseData(t, "testdata/a1a", 123)
+	prob, err := NewProblem(labels, examples)
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	param := NewParameter(C_SVC, RBF)
+	defer FreeParam(param)
+	param.SetGamma(1.0 / 123)
+
+	trained, err := Train(*prob, *param)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(trained)
+
+	total := 0
+	for _, n := range trained.NrSv() {
+		total += n
+	}
+
+	indices := trained.SvIndices()
+	if len(indices) != total || total != trained.TotalSv() {
+		t.Errorf("Expected %d support vector indices, got %d", total, len(indices))
+	}
+
+	for _, idx := range indices {
+		if idx < 1 || idx > len(labels) {
+			t.Fatal("Support vector index is not 1-based", idx)
+		}
+	}
+
+	var empty *SvmModel
+	if len(empty.NrSv()) != 0 || len(empty.SvIndices()) != 0 {
+		t.Error("Expected empty slices from a nil model")
+	}
+}
+
+func TestModelParameter(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	param := mdl.Parameter()
+	if param.SvmType() != C_SVC || param.Kernel() != RBF {
+		t.Error("Unexpected model parameter types", param.SvmType(), param.Kernel())
+	}
+
+	if math.Abs(param.Gamma()-0.00840336) > 1e-9 {
+		t.Error("Unexpected model gamma", param.Gamma())
+	}
+
+	if err := FreeParam(param); err == nil {
+		t.Error("Expected an error freeing a model's parameter view")
+	}
+
+	var empty *SvmModel
+	if empty.Parameter() != nil {
+		t.Error("Expected a nil parameter from a nil model")
+	}
+}
+
+func TestRho(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	if rho := mdl.Rho(); len(rho) != 1 || math.Abs(rho[0]-0.628337) > 1e-9 {
+		t.Error("Unexpected rho for the a1a model", rho)
+	}
+
+	multi, prob := trainThreeClass(t, NewParameter(C_SVC, LINEAR))
+	defer FreeProblem(prob)
+	defer FreeModel(multi)
+
+	if len(multi.Rho()) != 3 {
+		t.Error("Expected 3 pairwise rho values, got", multi.Rho())
+	}
+
+	_, values, err := multi.PredictValues(NewExample(1, []float64{0, 5}))
+	if err != nil || len(values) != 3 {
+		t.Error("Expected 3 decision values, got", values, err)
+	}
+
+	var empty *SvmModel
+	if len(empty.Rho()) != 0 {
+		t.Error("Expected an empty rho from a nil model")
+	}
+}
+
+func TestSupportsProbability(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	if mdl.SupportsProbability() {
+		t.Error("Expected the a1a model not to support probability estimates")
+	}
+
+	var empty *SvmModel
+	if empty.SupportsProbability() {
+		t.Error("Expected a nil model not to support probability estimates")
+	}
+}
+
+func TestTrainRetained(t *testing.T) {
+	labels, X := threeClassData()
+	prob, err := NewProblem(labels, X)
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	param := NewParameter(C_SVC, LINEAR)
+	defer FreeParam(param)
+
+	mdl, err := TrainRetained(prob, *param)
+	if err != nil {
+		t.Fatal("TrainRetained error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	if mdl.TrainingProblem() != prob {
+		t.Fatal("Expected the model to retain its training problem")
+	}
+
+	svs := mdl.SupportVectors()
+	for k, idx := range mdl.SvIndices() {
+		label, features, err := mdl.TrainingProblem().Example(idx - 1)
+		if err != nil {
+			t.Fatal("Example error was non-nil", err)
+		}
+
+		if label != labels[idx-1] || len(features) != 2 || features[0] != svs[k][0] || features[1] != svs[k][1] {
+			t.Errorf("Support vector %d does not match example %d: %v vs %v", k, idx-1, svs[k], features)
+		}
+
+		if features[0].Value != X[idx-1][0] {
+			t.Errorf("Example %d does not hold the original features %v", idx-1, X[idx-1])
+		}
+	}
+
+	if _, _, err := prob.Example(len(X)); err == nil {
+		t.Error("Expected an error for an example beyond the end of the problem")
+	}
+
+	plain, plainProb := trainThreeClass(t, NewParameter(C_SVC, LINEAR))
+	defer FreeProblem(plainProb)
+	defer FreeModel(plain)
+	if plain.TrainingProblem() != nil {
+		t.Error("Expected Train not to retain the problem")
+	}
+}
+
+func TestProbabilityLabels(t *testing.T) {
+	labels, X := threeClassData()
+	for i := range labels {
+		labels[i] = []float64{3, 1, 2}[int(labels[i])-1]
+	}
+
+	prob, err := NewProblem(labels, X)
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	param := NewParameter(NU_SVC, RBF)
+	defer FreeParam(param)
+	param.SetGammaAuto(2)
+	param.EnableProbability(true)
+
+	mdl, err := Train(*prob, *param)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	order, err := mdl.ProbabilityLabels()
+	if err != nil {
+		t.Fatal("ProbabilityLabels error was non-nil", err)
+	}
+
+	if !equalInts(order, mdl.Labels()) || !equalInts(order, []int{3, 1, 2}) {
+		t.Errorf("Expected probability labels [3 1 2] matching Labels, got %v", order)
+	}
+
+	exa := NewExample(1, X[1])
+	defer exa.Free()
+
+	label, probs, err := mdl.PredictProbability(exa)
+	if err != nil {
+		t.Fatal("PredictProbability error was non-nil", err)
+	}
+
+	best := 0
+	for i, p := range probs {
+		if p > probs[best] {
+			best = i
+		}
+	}
+
+	if float64(order[best]) != label || label != 1 {
+		t.Errorf("Expected the most probable class %d to be the predicted label %f", order[best], label)
+	}
+
+	loaded, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	if _, err := loaded.ProbabilityLabels(); !errors.Is(err, ErrNoProbability) {
+		t.Error("Expected a no probability error for the a1a model, got", err)
+	}
+}
+
+func TestProbabilityCoefficients(t *testing.T) {
+	param := NewParameter(C_SVC, RBF)
+	param.SetGammaAuto(2)
+	param.EnableProbability(true)
+
+	mdl, prob := trainThreeClass(t, param)
+	defer FreeProblem(prob)
+	defer FreeModel(mdl)
+
+	probA, probB, err := mdl.ProbabilityCoefficients()
+	if err != nil {
+		t.Fatal("ProbabilityCoefficients error was non-nil", err)
+	}
+
+	if len(probA) != 3 || len(probB) != 3 {
+		t.Errorf("Expected 3 coefficients for 3 classes, got %d and %d", len(probA), len(probB))
+	}
+
+	for i, a := range probA {
+		if a >= 0 || math.IsNaN(probB[i]) {
+			t.Errorf("Expected a decreasing sigmoid for pair %d, got A=%f B=%f", i, a, probB[i])
+		}
+	}
+
+	loaded, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	if _, _, err := loaded.ProbabilityCoefficients(); !errors.Is(err, ErrNoProbability) {
+		t.Error("Expected a no probability error for the a1a model, got", err)
+	}
+}
+
+func TestSupportVectorCoefficients(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	total := 0
+	for _, n := range mdl.NrSv() {
+		total += n
+	}
+
+	coef := mdl.SupportVectorCoefficients()
+	if len(coef) != mdl.NrClass()-1 || len(coef[0]) != total {
+		t.Errorf("Unexpected coefficient matrix dimensions %dx%d", len(coef), len(coef[0]))
+	}
+
+	svs := mdl.SupportVectors()
+	if len(svs) != total {
+		t.Errorf("Expected %d support vectors, got %d", total, len(svs))
+	}
+
+	if len(svs[0]) != 14 || svs[0][0] != (Pair{5, 1}) {
+		t.Error("Unexpected first support vector", svs[0])
+	}
+
+	var empty *SvmModel
+	if empty.SupportVectorCoefficients() != nil || empty.SupportVectors() != nil {
+		t.Error("Expected nil results from a nil model")
+	}
+}
+
+func TestProbabilityModel(t *testing.T) {
+	param := NewParameter(C_SVC, RBF)
+	param.SetGammaAuto(2)
+	param.EnableProbability(true)
+
+	mdl, prob := trainThreeClass(t, param)
+	defer FreeProblem(prob)
+	defer FreeModel(mdl)
+
+	if !mdl.SupportsProbability() {
+		t.Fatal("Expected a model trained with probability enabled to support probability estimates")
+	}
+
+	exa := NewExample(1, []float64{0.2, 4.8})
+	defer exa.Free()
+
+	label, probs, err := mdl.PredictProbability(exa)
+	if err != nil {
+		t.Fatal("PredictProbability error was non-nil", err)
+	}
+
+	if label != 1 || len(probs) != 3 {
+		t.Error("Unexpected probability prediction", label, probs)
+	}
+
+	sum := 0.0
+	for _, p := range probs {
+		sum += p
+	}
+
+	if math.Abs(sum-1) > 1e-6 {
+		t.Error("Expected probabilities to sum to 1, got", sum)
+	}
+}
+
+func TestSvrProbability(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	if v, err := mdl.SvrProbability(); err == nil || v != 0 {
+		t.Error("Expected an error for a classification model")
+	}
+
+	var empty *SvmModel
+	if _, err := empty.SvrProbability(); err == nil {
+		t.Error("Expected an error for a nil model")
+	}
+}
+
+func TestLoadInvalidFiles(t *testing.T) {
+	raw, err := os.ReadFile("testdata/a1a.model")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	cases := []struct {
+		name, contents, message string
+	}{
+		{"empty", "", "is empty"},
+		{"data", "+1 1:0.5 3:1\n", "missing svm_type header"},
+		{"truncated", strings.Join(strings.SplitN(string(raw), "\n", 4)[:3], "\n"), "unable to load model file"},
+	}
+
+	for _, c := range cases {
+		fn := filepath.Join(dir, c.name)
+		if err := os.WriteFile(fn, []byte(c.contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := Load(fn)
+		if !errors.Is(err, ErrLoadFailed) || !strings.Contains(err.Error(), c.message) {
+			t.Errorf("Loading %s: expected an error containing %q, got %v", c.name, c.message, err)
+		}
+	}
+
+	if _, err := Load("testdata/a1a.model"); err != nil {
+		t.Error("Model load error was non-nil for a valid model", err)
+	}
+}
+
 func TestLoadAndPredict(t *testing.T) {
 	mdl, err := Load("testdata/a1a.model")
 	if err != nil {
@@ -37,6 +675,227 @@ func TestLoadAndPredict(t *testing.T) {
 	}
 
 	if math.IsNaN(nv) || math.IsInf(nv, 0) {
-		t.Error(fmt.Sprintf("Predicted value is NaN or Infinity: %d", nv))
+		t.Error(fmt.Sprintf("Predicted value is NaN or Infinity: %f", nv))
+	}
+}
+
+func TestErrorKinds(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	prob, err := NewProblem([]float64{1, -1}, [][]float64{{1}, {-1}})
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	param := NewParameter(NU_SVC, LINEAR)
+	defer FreeParam(param)
+	param.SetNu(2)
+
+	var empty *SvmModel
+	_, nilModelErr := empty.Predict(NewExample(1, []float64{1}))
+	_, nilNodeErr := mdl.Predict(nil)
+	_, loadErr := Load("testdata/does-not-exist.model")
+	_, _, probErr := mdl.PredictProbability(NewExample(1, []float64{1}))
+
+	cases := []struct {
+		err  error
+		kind error
+	}{
+		{nilModelErr, ErrNilModel},
+		{FreeModel(nil), ErrNilModel},
+		{nilNodeErr, ErrNilNode},
+		{CheckParameter(*prob, *param), ErrInvalidParameter},
+		{SvmError{Kind: ErrTrainFailed, Message: "error while training. nil model returned"}, ErrTrainFailed},
+		{loadErr, ErrLoadFailed},
+		{mdl.Save("testdata/does-not-exist/a1a.model"), ErrSaveFailed},
+		{probErr, ErrNoProbability},
+	}
+
+	for i, c := range cases {
+		if !errors.Is(c.err, c.kind) {
+			t.Errorf("Case %d: expected %v to be %v", i, c.err, c.kind)
+		}
+	}
+
+	if errors.Is(loadErr, ErrTrainFailed) {
+		t.Error("Load error should not match ErrTrainFailed")
+	}
+
+	if loadErr.Error() != "unable to load model file: testdata/does-not-exist.model" {
+		t.Error("Error message changed", loadErr)
+	}
+}
+
+func TestDoubleFree(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	node := NewExample(1, []float64{1, 0, 1})
+	prob, err := NewProblem([]float64{1}, [][]float64{{1}})
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	param := NewParameter(C_SVC, RBF)
+
+	if err := FreeModel(mdl); err != nil {
+		t.Error("FreeModel error was non-nil", err)
+	}
+
+	if _, err := mdl.Predict(node); !errors.Is(err, ErrFreed) {
+		t.Error("Expected predict after free to return ErrFreed, got", err)
+	}
+
+	if err := node.Free(); err != nil {
+		t.Error("Free error was non-nil", err)
+	}
+
+	if err := FreeProblem(prob); err != nil {
+		t.Error("FreeProblem error was non-nil", err)
+	}
+
+	if err := FreeParam(param); err != nil {
+		t.Error("FreeParam error was non-nil", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, err := range []error{FreeModel(mdl), node.Free(), FreeProblem(prob), FreeParam(param)} {
+				if !errors.Is(err, ErrFreed) {
+					t.Error("Expected a second free to return ErrFreed, got", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCloser(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	prob, err := NewProblem([]float64{1}, [][]float64{{1}})
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+
+	closers := []io.Closer{mdl, NewParameter(C_SVC, RBF), prob, NewExample(1, []float64{1})}
+	for i, c := range closers {
+		if err := c.Close(); err != nil {
+			t.Errorf("Close %d error was non-nil: %v", i, err)
+		}
+	}
+
+	for i, c := range closers {
+		if err := c.Close(); !errors.Is(err, ErrFreed) {
+			t.Errorf("Expected a second Close %d to return ErrFreed, got %v", i, err)
+		}
+
+		var svmErr SvmError
+		if err := c.Close(); !errors.As(err, &svmErr) {
+			t.Errorf("Expected Close %d to return an SvmError, got %T", i, err)
+		}
+	}
+}
+
+// threeClassData returns three well separated clusters labelled 1, 2 and 3
+func threeClassData() ([]float64, [][]float64) {
+	centres := [][]float64{{0, 5}, {5, 0}, {-5, -5}}
+	var labels []float64
+	var X [][]float64
+	for i := 0; i < 30; i++ {
+		c := centres[i%3]
+		off := float64(i/3) * 0.05
+		labels = append(labels, float64(i%3+1))
+		X = append(X, []float64{c[0] + off, c[1] - off})
+	}
+
+	return labels, X
+}
+
+// trainThreeClass trains a model on threeClassData, taking ownership of param
+func trainThreeClass(tb testing.TB, param *SvmParameter) (*SvmModel, *SvmProblem) {
+	defer FreeParam(param)
+
+	labels, X := threeClassData()
+	prob, err := NewProblem(labels, X)
+	if err != nil {
+		tb.Fatal("NewProblem error was non-nil", err)
+	}
+
+	mdl, err := Train(*prob, *param)
+	if err != nil {
+		tb.Fatal("Train error was non-nil", err)
+	}
+
+	return mdl, prob
+}
+
+// trainProbabilitySvr trains an EPSILON_SVR model with probability
+// estimates on a noisy line
+func trainProbabilitySvr(tb testing.TB) (*SvmModel, *SvmProblem) {
+	var targets []float64
+	var X [][]float64
+	for i := 0; i < 40; i++ {
+		x := float64(i) / 10
+		targets = append(targets, 2*x+0.1*float64(i%3-1))
+		X = append(X, []float64{x})
+	}
+
+	prob, err := NewProblem(targets, X)
+	if err != nil {
+		tb.Fatal("NewProblem error was non-nil", err)
+	}
+
+	param := NewParameter(EPSILON_SVR, LINEAR)
+	defer FreeParam(param)
+	param.EnableProbability(true)
+
+	mdl, err := Train(*prob, *param)
+	if err != nil {
+		tb.Fatal("Train error was non-nil", err)
+	}
+
+	return mdl, prob
+}
+
+// readDenseData reads a libsvm formatted file into dense rows of the given width
+func readDenseData(tb testing.TB, filename string, width int) ([]float64, [][]float64) {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		tb.Fatal("Unable to read test data", err)
 	}
+
+	var labels []float64
+	var examples [][]float64
+	for _, line := range strings.Split(string(raw), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		label, _ := strconv.ParseFloat(fields[0], 64)
+		row := make([]float64, width)
+		for _, f := range fields[1:] {
+			kv := strings.SplitN(f, ":", 2)
+			idx, _ := strconv.Atoi(kv[0])
+			val, _ := strconv.ParseFloat(kv[1], 64)
+			row[idx-1] = val
+		}
+
+		labels = append(labels, label)
+		examples = append(examples, row)
+	}
+
+	return labels, examples
 }
@@ -0,0 +1,49 @@
+package libsvm
+
+// LinearWeights reconstructs the weight vector w = sum(sv_coef_i * sv_i) of a
+// model trained with the LINEAR kernel and a single decision function, which
+// covers two-class classification, one-class and regression models. w[j] is
+// the weight of feature index j+1, so its magnitude can serve as a feature
+// importance. For classification, a positive decision value dot(w, x) - rho
+// corresponds to the first class returned by Labels.
+func (mdl *SvmModel) LinearWeights() ([]float64, error) {
+	if err := checkModel(mdl, "compute linear weights of an svm model"); err != nil {
+		return nil, err
+	}
+
+	if kernel := mdl.Parameter().Kernel(); kernel != LINEAR {
+		return nil, SvmError{Message: "linear weights are only available for LINEAR kernel models, not " + kernel.String()}
+	}
+
+	if mdl.NrClass() != 2 {
+		return nil, SvmError{Message: "linear weights are only available for models with a single decision function"}
+	}
+
+	coef := mdl.SupportVectorCoefficients()[0]
+	var w []float64
+	for i, sv := range mdl.SupportVectors() {
+		for _, p := range sv {
+			for len(w) < p.Index {
+				w = append(w, 0)
+			}
+			w[p.Index-1] += coef[i] * p.Value
+		}
+	}
+
+	return w, nil
+}
+
+// ExportLinear returns a LINEAR kernel model with a single decision function
+// as a weight vector and bias, so that dot(weights, x) + bias is its decision
+// value, for deployment where LIBSVM is unavailable. The bias is -rho.
+// Classification models predict the first class returned by Labels when the
+// decision value is positive and the second otherwise; regression models
+// predict the decision value itself.
+func (mdl *SvmModel) ExportLinear() ([]float64, float64, error) {
+	w, err := mdl.LinearWeights()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return w, -mdl.Rho()[0], nil
+}
@@ -0,0 +1,90 @@
+package libsvm
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestLinearWeights(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var labels []float64
+	var X [][]float64
+	for i := 0; i < 60; i++ {
+		x := rnd.Float64()*2 - 1
+		label := 1.0
+		if x < 0 {
+			label = -1
+		}
+		labels = append(labels, label)
+		X = append(X, []float64{rnd.Float64(), x, rnd.Float64()})
+	}
+
+	clf := NewClassifier(WithKernel(LINEAR), WithC(10))
+	defer clf.Close()
+	if err := clf.Fit(labels, X); err != nil {
+		t.Fatal("Fit error was non-nil", err)
+	}
+
+	w, err := clf.est.model.LinearWeights()
+	if err != nil {
+		t.Fatal("LinearWeights error was non-nil", err)
+	}
+
+	if len(w) != 3 || math.Abs(w[1]) <= math.Abs(w[0]) || math.Abs(w[1]) <= math.Abs(w[2]) {
+		t.Error("Expected the second feature to dominate the weights, got", w)
+	}
+
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	if _, err := mdl.LinearWeights(); err == nil {
+		t.Error("Expected an error for an RBF model")
+	}
+}
+
+func TestExportLinear(t *testing.T) {
+	labels, X := separableData()
+	prob, err := NewProblem(labels, X)
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	param := NewParameter(C_SVC, LINEAR)
+	defer FreeParam(param)
+
+	mdl, err := Train(*prob, *param)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	w, bias, err := mdl.ExportLinear()
+	if err != nil {
+		t.Fatal("ExportLinear error was non-nil", err)
+	}
+
+	order := mdl.Labels()
+	rnd := rand.New(rand.NewSource(2))
+	for i := 0; i < 100; i++ {
+		x := []float64{rnd.Float64()*6 - 3, rnd.Float64()*6 - 3}
+		dec := bias
+		for j, v := range x {
+			if j < len(w) {
+				dec += w[j] * v
+			}
+		}
+
+		want := float64(order[1])
+		if dec > 0 {
+			want = float64(order[0])
+		}
+
+		if got, _ := mdl.PredictDense(x); got != want {
+			t.Errorf("Exported decision value %f for %v implies %f, Predict returned %f", dec, x, want, got)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package libsvm
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// modelMetadata is the JSON summary produced by MetadataJSON. Keys and type
+// names follow the LIBSVM model file header.
+type modelMetadata struct {
+	SvmType    string             `json:"svm_type"`
+	KernelType string             `json:"kernel_type"`
+	NrClass    int                `json:"nr_class"`
+	Labels     []int              `json:"labels,omitempty"`
+	TotalSv    int                `json:"total_sv"`
+	Rho        []float64          `json:"rho"`
+	Weights    map[string]float64 `json:"weights,omitempty"`
+}
+
+// MetadataJSON returns a JSON summary of the model holding its svm type,
+// kernel type, number of classes, class labels, total support vector count
+// and rho values, for use by dashboards and model registries.
+func (mdl *SvmModel) MetadataJSON() ([]byte, error) {
+	meta, err := mdl.metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(meta)
+}
+
+// metadata gathers the summary encoded by MetadataJSON
+func (mdl *SvmModel) metadata() (modelMetadata, error) {
+	if err := checkModel(mdl, "export svm model metadata"); err != nil {
+		return modelMetadata{}, err
+	}
+
+	return modelMetadata{
+		SvmType:    strings.ToLower(mdl.SvmType().String()),
+		KernelType: strings.ToLower(mdl.Parameter().Kernel().String()),
+		NrClass:    mdl.NrClass(),
+		Labels:     mdl.Labels(),
+		TotalSv:    mdl.TotalSv(),
+		Rho:        mdl.rho(),
+	}, nil
+}
@@ -0,0 +1,43 @@
+package libsvm
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMetadataJSON(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	raw, err := mdl.MetadataJSON()
+	if err != nil {
+		t.Fatal("MetadataJSON error was non-nil", err)
+	}
+
+	var meta struct {
+		SvmType    string    `json:"svm_type"`
+		KernelType string    `json:"kernel_type"`
+		NrClass    int       `json:"nr_class"`
+		Labels     []int     `json:"labels"`
+		TotalSv    int       `json:"total_sv"`
+		Rho        []float64 `json:"rho"`
+	}
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		t.Fatal("Unable to unmarshal metadata", err)
+	}
+
+	if meta.NrClass != mdl.NrClass() {
+		t.Errorf("Metadata nr_class %d does not match NrClass %d", meta.NrClass, mdl.NrClass())
+	}
+
+	if meta.SvmType != "c_svc" || meta.KernelType != "rbf" || meta.TotalSv != 754 || len(meta.Labels) != 2 || len(meta.Rho) != 1 {
+		t.Errorf("Unexpected metadata %s", raw)
+	}
+
+	var empty *SvmModel
+	if _, err := empty.MetadataJSON(); err == nil {
+		t.Error("Expected an error for a nil model")
+	}
+}
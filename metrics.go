@@ -0,0 +1,332 @@
+package libsvm
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Accuracy returns the fraction of predictions that exactly match the actual
+// labels, as reported by svm-predict for classification models.
+func Accuracy(predicted, actual []float64) (float64, error) {
+	if err := checkLengths(predicted, actual); err != nil {
+		return 0, err
+	}
+
+	hits := 0
+	for i, p := range predicted {
+		if p == actual[i] {
+			hits++
+		}
+	}
+
+	return float64(hits) / float64(len(actual)), nil
+}
+
+// MeanSquaredError returns the mean squared difference between predictions
+// and actual values, as reported by svm-predict for regression models.
+func MeanSquaredError(predicted, actual []float64) (float64, error) {
+	if err := checkLengths(predicted, actual); err != nil {
+		return 0, err
+	}
+
+	sum := 0.0
+	for i, p := range predicted {
+		d := p - actual[i]
+		sum += d * d
+	}
+
+	return sum / float64(len(actual)), nil
+}
+
+// ConfusionMatrix counts predictions by class, where m[i][j] is the number of
+// examples whose actual label is labels[i] and whose predicted label is
+// labels[j]. Passing the labels from a model's Labels keeps the ordering
+// consistent with the model. An error is returned for a label not in labels.
+func ConfusionMatrix(predicted, actual []float64, labels []float64) ([][]int, error) {
+	if err := checkLengths(predicted, actual); err != nil {
+		return nil, err
+	}
+
+	pos := make(map[float64]int, len(labels))
+	for i, l := range labels {
+		pos[l] = i
+	}
+
+	m := make([][]int, len(labels))
+	for i := range m {
+		m[i] = make([]int, len(labels))
+	}
+
+	for k := range actual {
+		i, ok := pos[actual[k]]
+		if !ok {
+			return nil, SvmError{Message: fmt.Sprintf("unknown actual label %g at index %d", actual[k], k)}
+		}
+
+		j, ok := pos[predicted[k]]
+		if !ok {
+			return nil, SvmError{Message: fmt.Sprintf("unknown predicted label %g at index %d", predicted[k], k)}
+		}
+
+		m[i][j]++
+	}
+
+	return m, nil
+}
+
+// BalancedAccuracy returns the mean per-class recall, taken over the classes
+// present in actual. Unlike Accuracy, it is not inflated by a classifier that
+// always predicts the majority class of an imbalanced data set.
+func BalancedAccuracy(predicted, actual []float64) (float64, error) {
+	if err := checkLengths(predicted, actual); err != nil {
+		return 0, err
+	}
+
+	counts := make(map[float64]int)
+	hits := make(map[float64]int)
+	for i, a := range actual {
+		counts[a]++
+		if predicted[i] == a {
+			hits[a]++
+		}
+	}
+
+	sum := 0.0
+	for label, n := range counts {
+		sum += float64(hits[label]) / float64(n)
+	}
+
+	return sum / float64(len(counts)), nil
+}
+
+// BaselineAccuracy returns the accuracy of always predicting the most common
+// label, the score any useful classifier on the same data must beat. It is
+// 0 for no labels.
+func BaselineAccuracy(labels []float64) float64 {
+	if len(labels) == 0 {
+		return 0
+	}
+
+	counts := make(map[float64]int)
+	most := 0
+	for _, label := range labels {
+		counts[label]++
+		if counts[label] > most {
+			most = counts[label]
+		}
+	}
+
+	return float64(most) / float64(len(labels))
+}
+
+// PrecisionRecall returns the precision and recall of a binary classifier
+// for the given positive label. Either is 0 when its denominator is, that is
+// when nothing was predicted or actually is positive.
+func PrecisionRecall(predicted, actual []float64, positiveLabel float64) (float64, float64, error) {
+	if err := checkLengths(predicted, actual); err != nil {
+		return 0, 0, err
+	}
+
+	tp, fp, fn := 0, 0, 0
+	for i, p := range predicted {
+		switch {
+		case p == positiveLabel && actual[i] == positiveLabel:
+			tp++
+		case p == positiveLabel:
+			fp++
+		case actual[i] == positiveLabel:
+			fn++
+		}
+	}
+
+	precision, recall := 0.0, 0.0
+	if tp+fp > 0 {
+		precision = float64(tp) / float64(tp+fp)
+	}
+	if tp+fn > 0 {
+		recall = float64(tp) / float64(tp+fn)
+	}
+
+	return precision, recall, nil
+}
+
+// F1Score returns the harmonic mean of precision and recall for the given
+// positive label, or 0 when both are 0.
+func F1Score(predicted, actual []float64, positiveLabel float64) (float64, error) {
+	precision, recall, err := PrecisionRecall(predicted, actual, positiveLabel)
+	if err != nil {
+		return 0, err
+	}
+
+	if precision+recall == 0 {
+		return 0, nil
+	}
+
+	return 2 * precision * recall / (precision + recall), nil
+}
+
+// ROCPoint is a point on an ROC curve: the rates reached by predicting
+// positive for every decision value at or above Threshold
+type ROCPoint struct {
+	Threshold         float64
+	FalsePositiveRate float64
+	TruePositiveRate  float64
+}
+
+// ROCAUC computes the ROC curve of a binary classifier from its decision
+// values, as returned by PredictValues, and the area under it by the
+// trapezoidal rule. Larger decision values must mean positiveLabel is more
+// likely; LIBSVM's decision values favour the model's first label, so negate
+// them when positiveLabel is Labels()[1]. The curve starts at (0, 0) with an
+// infinite threshold, and tied decision values produce a single point.
+func ROCAUC(decisionValues, actual []float64, positiveLabel float64) (float64, []ROCPoint, error) {
+	if err := checkLengths(decisionValues, actual); err != nil {
+		return 0, nil, err
+	}
+
+	order := make([]int, len(actual))
+	positives := 0
+	for i, a := range actual {
+		order[i] = i
+		if a == positiveLabel {
+			positives++
+		}
+	}
+
+	negatives := len(actual) - positives
+	if positives == 0 || negatives == 0 {
+		return 0, nil, SvmError{Message: "an ROC curve needs both positive and negative examples"}
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		return decisionValues[order[a]] > decisionValues[order[b]]
+	})
+
+	curve := []ROCPoint{{Threshold: math.Inf(1)}}
+	auc := 0.0
+	tp, fp := 0, 0
+	for k, i := range order {
+		if actual[i] == positiveLabel {
+			tp++
+		} else {
+			fp++
+		}
+
+		if k+1 < len(order) && decisionValues[order[k+1]] == decisionValues[i] {
+			continue
+		}
+
+		last := curve[len(curve)-1]
+		p := ROCPoint{
+			Threshold:         decisionValues[i],
+			FalsePositiveRate: float64(fp) / float64(negatives),
+			TruePositiveRate:  float64(tp) / float64(positives),
+		}
+		auc += (p.FalsePositiveRate - last.FalsePositiveRate) * (p.TruePositiveRate + last.TruePositiveRate) / 2
+		curve = append(curve, p)
+	}
+
+	return auc, curve, nil
+}
+
+// HammingLoss returns the fraction of sample and label pairs that are
+// predicted wrongly for multilabel data, such as the output of several
+// one-vs-rest binary models, where predicted[i] and actual[i] are the label
+// sets of sample i. The labels considered are all those appearing in either
+// argument; repeated labels within a set are counted once.
+func HammingLoss(predicted, actual [][]float64) (float64, error) {
+	pred, act, err := labelSets(predicted, actual)
+	if err != nil {
+		return 0, err
+	}
+
+	all := make(map[float64]bool)
+	wrong := 0
+	for i := range act {
+		for label := range pred[i] {
+			all[label] = true
+			if !act[i][label] {
+				wrong++
+			}
+		}
+		for label := range act[i] {
+			all[label] = true
+			if !pred[i][label] {
+				wrong++
+			}
+		}
+	}
+
+	if len(all) == 0 {
+		return 0, nil
+	}
+
+	return float64(wrong) / float64(len(act)*len(all)), nil
+}
+
+// JaccardScore returns the mean over samples of the size of the intersection
+// of the predicted and actual label sets divided by the size of their union.
+// A sample whose sets are both empty scores 1, since its prediction is exact.
+func JaccardScore(predicted, actual [][]float64) (float64, error) {
+	pred, act, err := labelSets(predicted, actual)
+	if err != nil {
+		return 0, err
+	}
+
+	sum := 0.0
+	for i := range act {
+		both := 0
+		for label := range pred[i] {
+			if act[i][label] {
+				both++
+			}
+		}
+
+		if union := len(pred[i]) + len(act[i]) - both; union == 0 {
+			sum++
+		} else {
+			sum += float64(both) / float64(union)
+		}
+	}
+
+	return sum / float64(len(act)), nil
+}
+
+// labelSets converts per-sample label lists into sets, checking the samples
+// line up and are non-empty
+func labelSets(predicted, actual [][]float64) ([]map[float64]bool, []map[float64]bool, error) {
+	if len(predicted) != len(actual) {
+		return nil, nil, SvmError{Message: fmt.Sprintf("prediction count %d does not match actual count %d", len(predicted), len(actual))}
+	}
+
+	if len(actual) == 0 {
+		return nil, nil, SvmError{Message: "no predictions to evaluate"}
+	}
+
+	toSets := func(rows [][]float64) []map[float64]bool {
+		sets := make([]map[float64]bool, len(rows))
+		for i, row := range rows {
+			sets[i] = make(map[float64]bool, len(row))
+			for _, label := range row {
+				sets[i][label] = true
+			}
+		}
+		return sets
+	}
+
+	return toSets(predicted), toSets(actual), nil
+}
+
+// checkLengths ensures predictions and actual values line up and are non-empty
+func checkLengths(predicted, actual []float64) error {
+	if len(predicted) != len(actual) {
+		return SvmError{Message: fmt.Sprintf("prediction count %d does not match actual count %d", len(predicted), len(actual))}
+	}
+
+	if len(actual) == 0 {
+		return SvmError{Message: "no predictions to evaluate"}
+	}
+
+	return nil
+}
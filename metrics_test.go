@@ -0,0 +1,179 @@
+package libsvm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAccuracy(t *testing.T) {
+	acc, err := Accuracy([]float64{1, -1, 1, 1}, []float64{1, -1, -1, 1})
+	if err != nil {
+		t.Fatal("Accuracy error was non-nil", err)
+	}
+
+	if acc != 0.75 {
+		t.Error("Expected accuracy of 0.75, got", acc)
+	}
+
+	if _, err := Accuracy([]float64{1}, []float64{1, 2}); err == nil {
+		t.Error("Expected an error for mismatched lengths")
+	}
+}
+
+func TestMeanSquaredError(t *testing.T) {
+	mse, err := MeanSquaredError([]float64{1, 2, 3}, []float64{1, 4, 2})
+	if err != nil {
+		t.Fatal("MeanSquaredError error was non-nil", err)
+	}
+
+	if math.Abs(mse-5.0/3) > 1e-12 {
+		t.Error("Expected mse of 5/3, got", mse)
+	}
+
+	if _, err := MeanSquaredError([]float64{1, 2}, []float64{1}); err == nil {
+		t.Error("Expected an error for mismatched lengths")
+	}
+}
+
+func TestConfusionMatrix(t *testing.T) {
+	actual := []float64{1, 1, 1, 2, 2, 3, 3, 3}
+	predicted := []float64{1, 2, 1, 2, 3, 3, 1, 3}
+
+	m, err := ConfusionMatrix(predicted, actual, []float64{3, 2, 1})
+	if err != nil {
+		t.Fatal("ConfusionMatrix error was non-nil", err)
+	}
+
+	want := [][]int{{2, 0, 1}, {1, 1, 0}, {0, 1, 2}}
+	for i := range want {
+		for j := range want[i] {
+			if m[i][j] != want[i][j] {
+				t.Errorf("Cell [%d][%d] was %d, expected %d", i, j, m[i][j], want[i][j])
+			}
+		}
+	}
+
+	if _, err := ConfusionMatrix([]float64{4}, []float64{1}, []float64{1, 2}); err == nil {
+		t.Error("Expected an error for an unknown label")
+	}
+
+	if _, err := ConfusionMatrix([]float64{1}, []float64{1, 2}, []float64{1, 2}); err == nil {
+		t.Error("Expected an error for mismatched lengths")
+	}
+}
+
+func TestImbalancedMetrics(t *testing.T) {
+	// 3 positives and 9 negatives: 2 true positives, 1 false negative and
+	// 2 false positives
+	actual := []float64{1, 1, 1, -1, -1, -1, -1, -1, -1, -1, -1, -1}
+	predicted := []float64{1, 1, -1, 1, 1, -1, -1, -1, -1, -1, -1, -1}
+
+	precision, recall, err := PrecisionRecall(predicted, actual, 1)
+	if err != nil {
+		t.Fatal("PrecisionRecall error was non-nil", err)
+	}
+
+	if math.Abs(precision-0.5) > 1e-12 || math.Abs(recall-2.0/3) > 1e-12 {
+		t.Errorf("Expected precision 0.5 and recall 2/3, got %f and %f", precision, recall)
+	}
+
+	f1, err := F1Score(predicted, actual, 1)
+	if err != nil {
+		t.Fatal("F1Score error was non-nil", err)
+	}
+
+	if math.Abs(f1-4.0/7) > 1e-12 {
+		t.Error("Expected F1 of 4/7, got", f1)
+	}
+
+	balanced, err := BalancedAccuracy(predicted, actual)
+	if err != nil {
+		t.Fatal("BalancedAccuracy error was non-nil", err)
+	}
+
+	if math.Abs(balanced-13.0/18) > 1e-12 {
+		t.Error("Expected balanced accuracy of 13/18, got", balanced)
+	}
+
+	if f1, _ := F1Score([]float64{-1, -1}, []float64{1, -1}, 1); f1 != 0 {
+		t.Error("Expected F1 of 0 with no positive predictions, got", f1)
+	}
+
+	if _, err := BalancedAccuracy(nil, nil); err == nil {
+		t.Error("Expected an error for no predictions")
+	}
+}
+
+func TestBaselineAccuracy(t *testing.T) {
+	labels := []float64{-1, -1, 1, -1, -1, 2, -1, 1}
+	if acc := BaselineAccuracy(labels); acc != 5.0/8 {
+		t.Error("Expected the majority fraction 5/8, got", acc)
+	}
+
+	if acc := BaselineAccuracy(nil); acc != 0 {
+		t.Error("Expected 0 for no labels, got", acc)
+	}
+}
+
+func TestMultilabelMetrics(t *testing.T) {
+	predicted := [][]float64{{1, 2}, {3}, {}, {1, 1}}
+	actual := [][]float64{{1}, {2, 3}, {}, {1}}
+
+	// labels {1, 2, 3} over 4 samples give 12 pairs, of which the extra 2 in
+	// the first sample and the missing 2 in the second are wrong
+	loss, err := HammingLoss(predicted, actual)
+	if err != nil {
+		t.Fatal("HammingLoss error was non-nil", err)
+	}
+
+	if math.Abs(loss-2.0/12) > 1e-12 {
+		t.Error("Expected a hamming loss of 1/6, got", loss)
+	}
+
+	// per sample: 1/2, 1/2, 1 for two empty sets and 1 for a repeated label
+	score, err := JaccardScore(predicted, actual)
+	if err != nil {
+		t.Fatal("JaccardScore error was non-nil", err)
+	}
+
+	if math.Abs(score-0.75) > 1e-12 {
+		t.Error("Expected a jaccard score of 0.75, got", score)
+	}
+
+	if _, err := HammingLoss(predicted[:1], actual); err == nil {
+		t.Error("Expected an error for mismatched lengths")
+	}
+
+	if _, err := JaccardScore(nil, nil); err == nil {
+		t.Error("Expected an error for no samples")
+	}
+}
+
+func TestROCAUC(t *testing.T) {
+	auc, curve, err := ROCAUC([]float64{2, 1.5, 1, -1, -2}, []float64{1, 1, 1, -1, -1}, 1)
+	if err != nil {
+		t.Fatal("ROCAUC error was non-nil", err)
+	}
+
+	if auc != 1 {
+		t.Error("Expected an AUC of 1 for separable data, got", auc)
+	}
+
+	if len(curve) != 6 || curve[3].FalsePositiveRate != 0 || curve[3].TruePositiveRate != 1 || curve[5].FalsePositiveRate != 1 {
+		t.Error("Unexpected ROC curve", curve)
+	}
+
+	// one positive tied with a negative counts as half a correct ranking
+	auc, curve, err = ROCAUC([]float64{3, 1, 1, 0}, []float64{1, -1, 1, -1}, 1)
+	if err != nil {
+		t.Fatal("ROCAUC error was non-nil", err)
+	}
+
+	if math.Abs(auc-0.875) > 1e-12 || len(curve) != 4 {
+		t.Error("Expected an AUC of 0.875 over 4 points, got", auc, curve)
+	}
+
+	if _, _, err := ROCAUC([]float64{1, 2}, []float64{1, 1}, 1); err == nil {
+		t.Error("Expected an error without negative examples")
+	}
+}
@@ -0,0 +1,63 @@
+package libsvm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// NamedModel pairs a model with names for its features, so that
+// introspection output reads "age: 0.4" rather than "3: 0.4".
+// FeatureNames[j] names feature index j+1.
+type NamedModel struct {
+	Model        *SvmModel
+	FeatureNames []string
+}
+
+// LinearWeights returns the model's linear weights, as computed by
+// SvmModel.LinearWeights, keyed by feature name. Every named feature is
+// present, with weight 0 if no support vector uses it. Duplicate names, and
+// features with a non-zero weight but no name, are rejected, since their
+// weights could not be told apart from those of named features.
+func (nm NamedModel) LinearWeights() (map[string]float64, error) {
+	w, err := nm.Model.LinearWeights()
+	if err != nil {
+		return nil, err
+	}
+
+	named := make(map[string]float64, len(w))
+	for _, name := range nm.FeatureNames {
+		if _, ok := named[name]; ok {
+			return nil, SvmError{Kind: ErrInvalidInput, Message: "duplicate feature name " + strconv.Quote(name)}
+		}
+		named[name] = 0
+	}
+
+	for j, v := range w {
+		if j < len(nm.FeatureNames) {
+			named[nm.FeatureNames[j]] = v
+		} else if v != 0 {
+			return nil, SvmError{Kind: ErrInvalidInput, Message: fmt.Sprintf("feature index %d has weight %g but no name, %d feature names were given", j+1, v, len(nm.FeatureNames))}
+		}
+	}
+
+	return named, nil
+}
+
+// MetadataJSON returns the same summary as SvmModel.MetadataJSON, adding a
+// "weights" object mapping feature names to weights for LINEAR kernel models
+// with a single decision function
+func (nm NamedModel) MetadataJSON() ([]byte, error) {
+	meta, err := nm.Model.metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	if meta.KernelType == "linear" && meta.NrClass == 2 {
+		if meta.Weights, err = nm.LinearWeights(); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(meta)
+}
@@ -0,0 +1,81 @@
+package libsvm
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNamedModel(t *testing.T) {
+	prob, err := NewProblem([]float64{1, 1, -1, -1}, [][]float64{{0.1, 1, 0.3}, {0.2, 2, 0.1}, {0.3, -1, 0.2}, {0.1, -2, 0.3}})
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	param := NewParameter(C_SVC, LINEAR)
+	defer FreeParam(param)
+
+	mdl, err := Train(*prob, *param)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	w, err := mdl.LinearWeights()
+	if err != nil {
+		t.Fatal("LinearWeights error was non-nil", err)
+	}
+
+	nm := NamedModel{Model: mdl, FeatureNames: []string{"noise_a", "signal", "noise_b", "unused"}}
+	raw, err := nm.MetadataJSON()
+	if err != nil {
+		t.Fatal("MetadataJSON error was non-nil", err)
+	}
+
+	var meta struct {
+		KernelType string             `json:"kernel_type"`
+		Weights    map[string]float64 `json:"weights"`
+	}
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		t.Fatal("Unable to unmarshal metadata", err)
+	}
+
+	if len(meta.Weights) != 4 || meta.Weights["unused"] != 0 {
+		t.Errorf("Expected weights for all four named features, got %s", raw)
+	}
+
+	for j, name := range nm.FeatureNames[:len(w)] {
+		if meta.Weights[name] != w[j] {
+			t.Errorf("Expected %s to have weight %f, got %f", name, w[j], meta.Weights[name])
+		}
+	}
+
+	nm.FeatureNames = []string{"a", "a"}
+	if _, err := nm.LinearWeights(); err == nil {
+		t.Error("Expected an error for duplicate feature names")
+	}
+
+	// an unnamed feature must not be keyed by its index, which could be
+	// another feature's name
+	nm.FeatureNames = []string{"3", "signal"}
+	if _, err := nm.LinearWeights(); !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "feature index 3") {
+		t.Error("Expected an error for a weighted feature without a name, got", err)
+	}
+
+	loaded, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	raw, err = NamedModel{Model: loaded, FeatureNames: []string{"a"}}.MetadataJSON()
+	if err != nil {
+		t.Fatal("MetadataJSON error was non-nil", err)
+	}
+
+	meta.Weights = nil
+	if json.Unmarshal(raw, &meta); meta.Weights != nil || meta.KernelType != "rbf" {
+		t.Errorf("Expected no weights for an RBF model, got %s", raw)
+	}
+}
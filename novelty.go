@@ -0,0 +1,46 @@
+package libsvm
+
+// NoveltyDetector is a convenience wrapper that trains a ONE_CLASS model on
+// normal data and flags examples that fall outside it. It owns the underlying
+// C resources, which are released by Close.
+type NoveltyDetector struct {
+	cfg config
+	est estimator
+}
+
+// NewNoveltyDetector creates a novelty detector configured by opts
+func NewNoveltyDetector(opts ...Option) *NoveltyDetector {
+	return &NoveltyDetector{cfg: newConfig(ONE_CLASS, opts)}
+}
+
+// Fit trains the detector on X, which should contain only normal examples.
+// nu, in (0, 1], is an upper bound on the fraction of training examples
+// treated as outliers. Calling Fit again replaces the previously trained model.
+func (nd *NoveltyDetector) Fit(X [][]float64, nu float64) error {
+	cfg := nd.cfg
+	cfg.nu = nu
+
+	labels := make([]float64, len(X))
+	for i := range labels {
+		labels[i] = 1
+	}
+
+	return nd.est.fit(cfg, labels, X)
+}
+
+// IsOutlier reports whether x lies outside the region learnt by Fit, which
+// LIBSVM indicates by predicting -1 rather than +1
+func (nd *NoveltyDetector) IsOutlier(x []float64) (bool, error) {
+	v, err := nd.est.predict(x)
+	if err != nil {
+		return false, err
+	}
+
+	return v < 0, nil
+}
+
+// Close releases the C resources owned by the detector
+func (nd *NoveltyDetector) Close() error {
+	nd.est.close()
+	return nil
+}
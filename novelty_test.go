@@ -0,0 +1,34 @@
+package libsvm
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestNoveltyDetector(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	X := make([][]float64, 100)
+	for i := range X {
+		X[i] = []float64{0.1 * rnd.NormFloat64(), 0.1 * rnd.NormFloat64()}
+	}
+
+	nd := NewNoveltyDetector()
+	defer nd.Close()
+
+	if err := nd.Fit(X, 0.1); err != nil {
+		t.Fatal("Fit error was non-nil", err)
+	}
+
+	outlier, err := nd.IsOutlier([]float64{5, 5})
+	if err != nil {
+		t.Fatal("IsOutlier error was non-nil", err)
+	}
+
+	if !outlier {
+		t.Error("Expected a far away point to be flagged as an outlier")
+	}
+
+	if outlier, _ := nd.IsOutlier([]float64{0, 0}); outlier {
+		t.Error("Expected the cluster centre not to be flagged as an outlier")
+	}
+}
@@ -0,0 +1,107 @@
+package libsvm
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// OnlineTrainer trains on data streamed in mini-batches that would not fit
+// in memory as a single problem. LIBSVM has no true online mode, so the
+// trainer keeps a uniform random sample of at most capacity examples from
+// everything passed to Partial, using reservoir sampling, and Finalize trains
+// on that sample alone. The model is therefore an approximation of the one a
+// full training run would give, closer the larger the capacity, and rare
+// classes may be missed entirely if they make up a small share of the
+// stream. An OnlineTrainer is not safe for concurrent use.
+type OnlineTrainer struct {
+	params   Params
+	capacity int
+	rnd      *rand.Rand
+	seen     int
+	labels   []float64
+	rows     [][]float64
+}
+
+// NewOnlineTrainer returns a trainer that samples up to capacity examples,
+// shuffling with the given seed so that runs are reproducible, and trains
+// them with params
+func NewOnlineTrainer(params Params, capacity int, seed int64) (*OnlineTrainer, error) {
+	if capacity < 1 {
+		return nil, SvmError{Message: fmt.Sprintf("invalid capacity %d, at least 1 example must be kept", capacity)}
+	}
+
+	return &OnlineTrainer{params: params, capacity: capacity, rnd: rand.New(rand.NewSource(seed))}, nil
+}
+
+// Partial adds a mini-batch of dense examples, indexed from 1 as in
+// NewProblem. Rows that are kept are copied, so X may be reused afterwards.
+func (tr *OnlineTrainer) Partial(labels []float64, X [][]float64) error {
+	if len(labels) != len(X) {
+		return SvmError{Message: fmt.Sprintf("label count %d does not match example count %d", len(labels), len(X))}
+	}
+
+	for i, row := range X {
+		if len(row) == 0 {
+			return SvmError{Message: fmt.Sprintf("example %d is empty", i)}
+		}
+	}
+
+	for i, row := range X {
+		tr.seen++
+		slot := len(tr.rows)
+		if slot == tr.capacity {
+			slot = tr.rnd.Intn(tr.seen)
+			if slot >= tr.capacity {
+				continue
+			}
+		}
+
+		kept := append([]float64(nil), row...)
+		if slot == len(tr.rows) {
+			tr.labels = append(tr.labels, labels[i])
+			tr.rows = append(tr.rows, kept)
+		} else {
+			tr.labels[slot] = labels[i]
+			tr.rows[slot] = kept
+		}
+	}
+
+	return nil
+}
+
+// Seen returns the number of examples passed to Partial so far
+func (tr *OnlineTrainer) Seen() int {
+	return tr.seen
+}
+
+// Len returns the number of examples currently sampled for training
+func (tr *OnlineTrainer) Len() int {
+	return len(tr.rows)
+}
+
+// Finalize trains a model on the sampled examples. The trainer keeps its
+// sample, so more batches may be added and Finalize called again. The
+// returned model owns the problem and parameter it was trained with.
+func (tr *OnlineTrainer) Finalize() (*SvmModel, error) {
+	param, err := tr.params.ToSvmParameter()
+	if err != nil {
+		return nil, err
+	}
+
+	prob, err := NewProblem(tr.labels, tr.rows)
+	if err != nil {
+		FreeParam(param)
+		return nil, err
+	}
+
+	mdl, err := Train(*prob, *param)
+	if err != nil {
+		FreeParam(param)
+		FreeProblem(prob)
+		return nil, err
+	}
+
+	mdl.own(prob)
+	mdl.ownParam(param)
+	return mdl, nil
+}
@@ -0,0 +1,76 @@
+package libsvm
+
+import "testing"
+
+func TestOnlineTrainer(t *testing.T) {
+	params := DefaultParams(C_SVC, RBF)
+	params.Gamma = 0.5
+
+	tr, err := NewOnlineTrainer(params, 20, 1)
+	if err != nil {
+		t.Fatal("NewOnlineTrainer error was non-nil", err)
+	}
+
+	labels, X := threeClassData()
+	for start := 0; start < len(X); start += 7 {
+		end := start + 7
+		if end > len(X) {
+			end = len(X)
+		}
+
+		if err := tr.Partial(labels[start:end], X[start:end]); err != nil {
+			t.Fatal("Partial error was non-nil", err)
+		}
+	}
+
+	if tr.Seen() != len(X) || tr.Len() != 20 {
+		t.Errorf("Expected 20 of %d examples kept, got %d of %d", len(X), tr.Len(), tr.Seen())
+	}
+
+	mdl, err := tr.Finalize()
+	if err != nil {
+		t.Fatal("Finalize error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	correct := 0
+	for i, exa := range X {
+		if v, err := mdl.PredictDense(exa); err == nil && v == labels[i] {
+			correct++
+		}
+	}
+
+	if correct < len(X)*9/10 {
+		t.Errorf("Expected the sampled model to classify most examples, got %d of %d", correct, len(X))
+	}
+
+	if err := tr.Partial([]float64{1}, nil); err == nil {
+		t.Error("Expected an error for mismatched labels and examples")
+	}
+
+	params.ClassWeights = map[int]float64{1: 2, 3: 0.5}
+	weighted, err := NewOnlineTrainer(params, 20, 1)
+	if err != nil {
+		t.Fatal("NewOnlineTrainer error was non-nil", err)
+	}
+
+	if err := weighted.Partial(labels, X); err != nil {
+		t.Fatal("Partial error was non-nil", err)
+	}
+
+	wmdl, err := weighted.Finalize()
+	if err != nil {
+		t.Fatal("Finalize error was non-nil", err)
+	}
+	defer FreeModel(wmdl)
+
+	// svm_train shares the class weight arrays with the parameter, which
+	// must stay alive for as long as the model
+	if wmdl.handle.param == nil || wmdl.object.param.weight != wmdl.handle.param.object.weight || wmdl.object.param.nr_weight != 2 {
+		t.Error("Expected the model to own the parameter holding its class weights")
+	}
+
+	if _, err := NewOnlineTrainer(params, 0, 1); err == nil {
+		t.Error("Expected an error for a zero capacity")
+	}
+}
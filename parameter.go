@@ -0,0 +1,445 @@
+package libsvm
+
+/*
+#include <svm.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"sync/atomic"
+	"unsafe"
+)
+
+// NewParameter allocates a parameter set for the given svm and kernel types,
+// initialised with the same defaults svm-train uses. Gamma is left at 0, so
+// callers using a POLY, RBF or SIGMOID kernel are responsible for setting it
+// (svm-train defaults it to 1/num_features) before training.
+// The parameter must be released with FreeParam.
+func NewParameter(svmType SvmType, kernel KernelType) *SvmParameter {
+	obj := (*C.struct_svm_parameter)(C.calloc(1, C.sizeof_struct_svm_parameter))
+
+	obj.svm_type = C.int(svmType)
+	obj.kernel_type = C.int(kernel)
+	obj.degree = 3
+	obj.gamma = 0
+	obj.coef0 = 0
+	obj.nu = 0.5
+	obj.cache_size = 100
+	obj.C = 1
+	obj.eps = 1e-3
+	obj.p = 0.1
+	obj.shrinking = 1
+	obj.probability = 0
+	obj.nr_weight = 0
+	obj.weight_label = nil
+	obj.weight = nil
+
+	return &SvmParameter{object: obj}
+}
+
+// SetC sets the cost parameter used by C_SVC, EPSILON_SVR and NU_SVR
+func (param *SvmParameter) SetC(c float64) error {
+	if err := param.checkWritable("SetC"); err != nil {
+		return err
+	}
+
+	param.object.C = C.double(c)
+	return nil
+}
+
+// SetGamma sets the kernel gamma used by POLY, RBF and SIGMOID kernels
+func (param *SvmParameter) SetGamma(gamma float64) error {
+	if err := param.checkWritable("SetGamma"); err != nil {
+		return err
+	}
+
+	param.object.gamma = C.double(gamma)
+	return nil
+}
+
+// SetGammaAuto sets gamma to 1/numFeatures, the default svm-train uses and
+// the scikit-learn "auto" setting. It is a no-op if numFeatures is not positive.
+func (param *SvmParameter) SetGammaAuto(numFeatures int) error {
+	if err := param.checkWritable("SetGammaAuto"); err != nil {
+		return err
+	}
+
+	if numFeatures <= 0 {
+		return nil
+	}
+
+	param.object.gamma = C.double(1.0 / float64(numFeatures))
+	return nil
+}
+
+// SetGammaScale sets gamma to 1/(numFeatures*variance), the scikit-learn
+// "scale" setting, where variance is the variance of all feature values.
+// It falls back to SetGammaAuto if variance is not positive.
+func (param *SvmParameter) SetGammaScale(numFeatures int, variance float64) error {
+	if variance <= 0 {
+		return param.SetGammaAuto(numFeatures)
+	}
+
+	if err := param.checkWritable("SetGammaScale"); err != nil {
+		return err
+	}
+
+	if numFeatures <= 0 {
+		return nil
+	}
+
+	param.object.gamma = C.double(1.0 / (float64(numFeatures) * variance))
+	return nil
+}
+
+// SetDegree sets the degree of the POLY kernel
+func (param *SvmParameter) SetDegree(degree int) error {
+	if err := param.checkWritable("SetDegree"); err != nil {
+		return err
+	}
+
+	param.object.degree = C.int(degree)
+	return nil
+}
+
+// SetCoef0 sets the independent term used by POLY and SIGMOID kernels
+func (param *SvmParameter) SetCoef0(coef0 float64) error {
+	if err := param.checkWritable("SetCoef0"); err != nil {
+		return err
+	}
+
+	param.object.coef0 = C.double(coef0)
+	return nil
+}
+
+// SetNu sets the nu parameter used by NU_SVC, ONE_CLASS and NU_SVR
+func (param *SvmParameter) SetNu(nu float64) error {
+	if err := param.checkWritable("SetNu"); err != nil {
+		return err
+	}
+
+	param.object.nu = C.double(nu)
+	return nil
+}
+
+// SetP sets the epsilon in the loss function of EPSILON_SVR
+func (param *SvmParameter) SetP(p float64) error {
+	if err := param.checkWritable("SetP"); err != nil {
+		return err
+	}
+
+	param.object.p = C.double(p)
+	return nil
+}
+
+// SetEps sets the tolerance of the termination criterion, 1e-3 by default.
+// Training stops once the optimality conditions hold to within eps, so a
+// larger value finishes sooner with a slightly less optimal model, while a
+// smaller one can take many more iterations for little change in the
+// predictions. TrainWithSummary reports the resulting objective values and
+// whether the solver converged.
+func (param *SvmParameter) SetEps(eps float64) error {
+	if err := param.checkWritable("SetEps"); err != nil {
+		return err
+	}
+
+	param.object.eps = C.double(eps)
+	return nil
+}
+
+// SetCacheSize sets the kernel cache size in MB, 100 by default. LIBSVM
+// caches the kernel matrix rows it computes, so on large problems a bigger
+// cache can cut training time considerably, at the cost of that much memory
+// per concurrent training run. Once the cache holds the whole matrix, about
+// l*l*4 bytes for l examples, more makes no difference.
+func (param *SvmParameter) SetCacheSize(mb float64) error {
+	if err := param.checkWritable("SetCacheSize"); err != nil {
+		return err
+	}
+
+	param.object.cache_size = C.double(mb)
+	return nil
+}
+
+// SetCacheSizeAuto sets the kernel cache size to a quarter of the memory the
+// system reports as available, and never below the 100MB default, returning
+// the size chosen. Available memory is only known on Linux; elsewhere the
+// default is kept.
+func (param *SvmParameter) SetCacheSizeAuto() (float64, error) {
+	mb := 100.0
+	if avail, ok := availableMemoryMB(); ok && avail/4 > mb {
+		mb = avail / 4
+	}
+
+	if err := param.SetCacheSize(mb); err != nil {
+		return 0, err
+	}
+
+	return mb, nil
+}
+
+// availableMemoryMB reads MemAvailable from /proc/meminfo
+func availableMemoryMB() (float64, bool) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var kb float64
+		if _, err := fmt.Sscanf(scanner.Text(), "MemAvailable: %g kB", &kb); err == nil {
+			return kb / 1024, true
+		}
+	}
+
+	return 0, false
+}
+
+// CacheSize returns the kernel cache size in MB
+func (param *SvmParameter) CacheSize() float64 {
+	return float64(param.object.cache_size)
+}
+
+// SetShrinking enables or disables the shrinking heuristics, which are on by
+// default. Shrinking temporarily drops variables that look bound from the
+// optimisation and usually speeds training up considerably, but can slow it
+// down when few variables end up at their bounds, e.g. with a large C. The
+// trained models are the same within the stopping tolerance, so it is worth
+// timing both on your own data; BenchmarkShrinking in the tests shows how.
+func (param *SvmParameter) SetShrinking(shrinking bool) error {
+	if err := param.checkWritable("SetShrinking"); err != nil {
+		return err
+	}
+
+	if shrinking {
+		param.object.shrinking = 1
+	} else {
+		param.object.shrinking = 0
+	}
+	return nil
+}
+
+// SvmType returns the type of svm the parameters are for
+func (param *SvmParameter) SvmType() SvmType {
+	return SvmType(param.object.svm_type)
+}
+
+// Kernel returns the kernel type
+func (param *SvmParameter) Kernel() KernelType {
+	return KernelType(param.object.kernel_type)
+}
+
+// C returns the cost parameter
+func (param *SvmParameter) C() float64 {
+	return float64(param.object.C)
+}
+
+// Gamma returns the kernel gamma
+func (param *SvmParameter) Gamma() float64 {
+	return float64(param.object.gamma)
+}
+
+// Degree returns the degree of the POLY kernel
+func (param *SvmParameter) Degree() int {
+	return int(param.object.degree)
+}
+
+// Coef0 returns the independent term of the POLY and SIGMOID kernels
+func (param *SvmParameter) Coef0() float64 {
+	return float64(param.object.coef0)
+}
+
+// Eps returns the tolerance of the termination criterion
+func (param *SvmParameter) Eps() float64 {
+	return float64(param.object.eps)
+}
+
+// Nu returns the nu parameter
+func (param *SvmParameter) Nu() float64 {
+	return float64(param.object.nu)
+}
+
+// P returns the epsilon in the loss function of EPSILON_SVR
+func (param *SvmParameter) P() float64 {
+	return float64(param.object.p)
+}
+
+// Shrinking reports whether the shrinking heuristics are enabled
+func (param *SvmParameter) Shrinking() bool {
+	return param.object.shrinking != 0
+}
+
+// CheckParameter asks LIBSVM whether the parameters are feasible for the
+// given problem. It returns nil if they are, or an error describing the problem.
+func CheckParameter(prob SvmProblem, param SvmParameter) error {
+	if prob.object == nil {
+		return SvmError{Message: "problem object's internal svm_problem pointer is nil when attempting to check svm parameters"}
+	}
+
+	if param.object == nil {
+		return SvmError{Message: "param object's internal svm_parameter pointer is nil when attempting to check svm parameters"}
+	}
+
+	if msg := C.svm_check_parameter(prob.object, param.object); msg != nil {
+		return SvmError{Kind: ErrInvalidParameter, Message: C.GoString(msg)}
+	}
+
+	return nil
+}
+
+// checkParams runs Validate and then CheckParameter, as every training entry
+// point does before handing the parameters to LIBSVM
+func checkParams(prob SvmProblem, param SvmParameter) error {
+	if param.object != nil {
+		if err := param.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return CheckParameter(prob, param)
+}
+
+// EnableProbability controls whether training also fits the models needed for
+// probability estimates, so that the trained model can be used with
+// PredictProbability. It makes training noticeably slower.
+func (param *SvmParameter) EnableProbability(enable bool) error {
+	if err := param.checkWritable("EnableProbability"); err != nil {
+		return err
+	}
+
+	if enable {
+		param.object.probability = 1
+	} else {
+		param.object.probability = 0
+	}
+	return nil
+}
+
+// SetClassWeights sets per class penalty weights, scaling C by weights[label]
+// for the given class labels, which helps with imbalanced data. Any previous
+// weights are replaced; an empty map clears them.
+func (param *SvmParameter) SetClassWeights(weights map[int]float64) error {
+	if err := param.checkWritable("SetClassWeights"); err != nil {
+		return err
+	}
+
+	C.free(unsafe.Pointer(param.object.weight_label))
+	C.free(unsafe.Pointer(param.object.weight))
+	param.object.weight_label = nil
+	param.object.weight = nil
+	param.object.nr_weight = 0
+
+	if len(weights) == 0 {
+		return nil
+	}
+
+	labels := make([]int, 0, len(weights))
+	for label := range weights {
+		labels = append(labels, label)
+	}
+	sort.Ints(labels)
+
+	n := len(labels)
+	param.object.weight_label = (*C.int)(C.calloc(C.size_t(n), C.sizeof_int))
+	param.object.weight = (*C.double)(C.calloc(C.size_t(n), C.sizeof_double))
+	param.object.nr_weight = C.int(n)
+
+	wl := unsafe.Slice(param.object.weight_label, n)
+	w := unsafe.Slice(param.object.weight, n)
+	for i, label := range labels {
+		wl[i] = C.int(label)
+		w[i] = C.double(weights[label])
+	}
+	return nil
+}
+
+// checkWritable ensures the parameter may be modified: a model's Parameter
+// view aliases memory the C model owns, including its class weight arrays
+func (param *SvmParameter) checkWritable(setter string) error {
+	if param.model != nil {
+		return SvmError{Kind: ErrInvalidParameter, Message: setter + " called on a model's parameter view, which cannot be modified; use Clone for an adjustable copy"}
+	}
+
+	return nil
+}
+
+// Clone returns an independent deep copy of the parameter, including its
+// class weights, which can be adjusted and must be freed separately from the
+// original. Cloning a model's Parameter view gives a regular parameter with
+// the model's settings but no class weights, since a model does not own the
+// weight arrays it was trained with.
+func (param *SvmParameter) Clone() *SvmParameter {
+	obj := (*C.struct_svm_parameter)(C.calloc(1, C.sizeof_struct_svm_parameter))
+	*obj = *param.object
+	obj.weight_label = nil
+	obj.weight = nil
+	obj.nr_weight = 0
+
+	if n := int(param.object.nr_weight); n > 0 && param.model == nil {
+		obj.weight_label = (*C.int)(C.calloc(C.size_t(n), C.sizeof_int))
+		obj.weight = (*C.double)(C.calloc(C.size_t(n), C.sizeof_double))
+		obj.nr_weight = C.int(n)
+		copy(unsafe.Slice(obj.weight_label, n), unsafe.Slice(param.object.weight_label, n))
+		copy(unsafe.Slice(obj.weight, n), unsafe.Slice(param.object.weight, n))
+	}
+
+	return &SvmParameter{object: obj}
+}
+
+// copyParam makes a shallow copy of the parameter in C memory so that callers
+// can adjust it without touching the original. The copy shares the original's
+// class weight arrays, so it must be released with C.free rather than FreeParam.
+func copyParam(param SvmParameter) *SvmParameter {
+	obj := (*C.struct_svm_parameter)(C.calloc(1, C.sizeof_struct_svm_parameter))
+	*obj = *param.object
+	return &SvmParameter{object: obj}
+}
+
+// freeParamCopy releases a parameter made by copyParam
+func freeParamCopy(param *SvmParameter) {
+	C.free(unsafe.Pointer(param.object))
+	param.object = nil
+}
+
+// FreeParam will free the underlying svm_parameter structure, including any
+// class weights attached to it. It is equivalent to param.Close().
+func FreeParam(param *SvmParameter) error {
+	return param.Close()
+}
+
+// Close will free the underlying svm_parameter structure, including any
+// class weights attached to it
+func (param *SvmParameter) Close() error {
+
+	if param == nil {
+		return SvmError{Message: "nil param when attempting to free an svm parameter"}
+	}
+
+	if atomic.LoadInt32(&param.freed) != 0 {
+		return SvmError{Kind: ErrFreed, Message: "svm parameter has already been freed"}
+	}
+
+	if param.object == nil {
+		return SvmError{Message: "param object's internal svm_parameter pointer is nil when attempting to free an svm parameter"}
+	}
+
+	if param.model != nil {
+		return SvmError{Message: "param belongs to a model and cannot be freed separately"}
+	}
+
+	if !atomic.CompareAndSwapInt32(&param.freed, 0, 1) {
+		return SvmError{Kind: ErrFreed, Message: "svm parameter has already been freed"}
+	}
+
+	C.svm_destroy_param(param.object)
+	C.free(unsafe.Pointer(param.object))
+	param.object = nil
+	return nil
+}
@@ -0,0 +1,410 @@
+package libsvm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestNewParameter(t *testing.T) {
+	param := NewParameter(C_SVC, RBF)
+	if param == nil || param.object == nil {
+		t.Fatal("Error the returned parameter was nil")
+	}
+
+	if SvmType(param.object.svm_type) != C_SVC || KernelType(param.object.kernel_type) != RBF {
+		t.Error("Parameter svm or kernel type was not set")
+	}
+
+	if param.object.C != 1 || param.object.gamma != 0 || param.object.cache_size != 100 ||
+		param.object.eps != 1e-3 || param.object.shrinking != 1 || param.object.probability != 0 ||
+		param.object.nr_weight != 0 {
+		t.Error("Parameter defaults do not match svm-train defaults")
+	}
+
+	param.SetC(10)
+	param.SetGamma(0.5)
+	param.SetShrinking(false)
+	if param.object.C != 10 || param.object.gamma != 0.5 || param.object.shrinking != 0 {
+		t.Error("Parameter setters did not update the underlying struct")
+	}
+
+	if err := FreeParam(param); err != nil {
+		t.Error("FreeParam error was non-nil", err)
+	}
+
+	if param.object != nil {
+		t.Error("FreeParam did not clear the internal pointer")
+	}
+}
+
+func TestParameterClone(t *testing.T) {
+	param := NewParameter(C_SVC, RBF)
+	defer FreeParam(param)
+	param.SetC(2)
+	param.SetClassWeights(map[int]float64{1: 2, -1: 0.5})
+
+	clone := param.Clone()
+	clone.SetC(10)
+	clone.SetGamma(0.25)
+	clone.SetClassWeights(map[int]float64{1: 3})
+
+	if param.C() != 2 || param.Gamma() != 0 {
+		t.Error("Changing the clone modified the original", param.C(), param.Gamma())
+	}
+
+	if param.object.nr_weight != 2 || unsafe.Slice(param.object.weight, 2)[1] != 2 {
+		t.Error("Changing the clone's class weights modified the original")
+	}
+
+	if err := FreeParam(clone); err != nil {
+		t.Error("FreeParam error was non-nil for the clone", err)
+	}
+
+	again := param.Clone()
+	defer FreeParam(again)
+	if again.C() != 2 || again.object.nr_weight != 2 || again.object.weight == param.object.weight {
+		t.Error("Expected the clone to hold its own copy of the class weights")
+	}
+}
+
+func TestParameterViewSetters(t *testing.T) {
+	param := NewParameter(C_SVC, LINEAR)
+	defer FreeParam(param)
+	param.SetClassWeights(map[int]float64{1: 2})
+
+	labels, X := threeClassData()
+	prob, err := NewProblem(labels, X)
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	// the model shares the class weight arrays of param, which must outlive it
+	mdl, err := Train(*prob, *param)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	view := mdl.Parameter()
+	weights := mdl.object.param.weight
+	checks := map[string]error{
+		"SetC":              view.SetC(5),
+		"SetGamma":          view.SetGamma(1),
+		"SetGammaAuto":      view.SetGammaAuto(2),
+		"SetEps":            view.SetEps(1),
+		"SetShrinking":      view.SetShrinking(false),
+		"EnableProbability": view.EnableProbability(true),
+		"SetClassWeights":   view.SetClassWeights(nil),
+	}
+	for name, err := range checks {
+		if !errors.Is(err, ErrInvalidParameter) || !strings.Contains(err.Error(), name) {
+			t.Errorf("Expected %s on a parameter view to fail, got %v", name, err)
+		}
+	}
+
+	if view.C() != 1 || mdl.object.param.weight != weights || mdl.object.param.nr_weight != 1 {
+		t.Error("Expected the model's parameters to be left unchanged")
+	}
+
+	if err := param.SetC(2); err != nil {
+		t.Error("SetC error was non-nil on a regular parameter", err)
+	}
+}
+
+func TestCheckParameterInvalidNu(t *testing.T) {
+	prob, err := NewProblem([]float64{1, -1}, [][]float64{{1, 0}, {0, 1}})
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	param := NewParameter(NU_SVC, LINEAR)
+	defer FreeParam(param)
+	param.SetNu(2.0)
+
+	err = CheckParameter(*prob, *param)
+	if err == nil || !strings.Contains(err.Error(), "nu") {
+		t.Error("Expected an error mentioning the invalid nu", err)
+	}
+
+	if _, err := Train(*prob, *param); err == nil || !strings.Contains(err.Error(), "nu") {
+		t.Error("Expected Train to return the parameter validation error", err)
+	}
+}
+
+func TestEpsAgreement(t *testing.T) {
+	prob, param, _ := a1aProblem(t)
+	defer FreeProblem(prob)
+	defer FreeParam(param)
+
+	if param.Eps() != 1e-3 {
+		t.Error("Expected the default eps to be 1e-3, got", param.Eps())
+	}
+
+	param.SetEps(1e-2)
+	loose, looseSummary, err := TrainWithSummary(*prob, *param)
+	if err != nil {
+		t.Fatal("TrainWithSummary error was non-nil", err)
+	}
+	defer FreeModel(loose)
+
+	param.SetEps(1e-5)
+	tight, tightSummary, err := TrainWithSummary(*prob, *param)
+	if err != nil {
+		t.Fatal("TrainWithSummary error was non-nil", err)
+	}
+	defer FreeModel(tight)
+
+	if tightSummary.Iterations[0] < looseSummary.Iterations[0] {
+		t.Errorf("Expected a tighter eps to need more iterations, got %d and %d", tightSummary.Iterations[0], looseSummary.Iterations[0])
+	}
+
+	_, examples := readDenseData(t, "testdata/a1a.t", 123)
+	differ := 0
+	for _, ex := range examples {
+		a, _ := loose.PredictDense(ex)
+		b, _ := tight.PredictDense(ex)
+		if a != b {
+			differ++
+		}
+	}
+
+	if differ > len(examples)/50 {
+		t.Errorf("%d of %d predictions differed between eps 1e-2 and 1e-5", differ, len(examples))
+	}
+}
+
+// BenchmarkEps trains the same problem with a loose and a tight termination
+// tolerance
+func BenchmarkEps(b *testing.B) {
+	prob, param, _ := a1aProblem(b)
+	defer FreeProblem(prob)
+	defer FreeParam(param)
+	SetQuiet(true)
+	defer SetQuiet(false)
+
+	for _, eps := range []float64{1e-2, 1e-5} {
+		param.SetEps(eps)
+		b.Run(fmt.Sprintf("eps=%g", eps), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				mdl, err := Train(*prob, *param)
+				if err != nil {
+					b.Fatal("Train error was non-nil", err)
+				}
+				FreeModel(mdl)
+			}
+		})
+	}
+}
+
+func TestClassWeights(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var labels []float64
+	var examples [][]float64
+	for i := 0; i < 200; i++ {
+		labels = append(labels, -1)
+		examples = append(examples, []float64{rnd.NormFloat64(), rnd.NormFloat64()})
+	}
+	for i := 0; i < 20; i++ {
+		labels = append(labels, 1)
+		examples = append(examples, []float64{1.5 + rnd.NormFloat64(), 1.5 + rnd.NormFloat64()})
+	}
+
+	prob, err := NewProblem(labels, examples)
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	recall := func(param *SvmParameter) float64 {
+		mdl, err := Train(*prob, *param)
+		if err != nil {
+			t.Fatal("Train error was non-nil", err)
+		}
+		defer FreeModel(mdl)
+
+		hits := 0
+		for i, ex := range examples[200:] {
+			exa := NewExample(1, ex)
+			if v, _ := mdl.Predict(exa); v == labels[200+i] {
+				hits++
+			}
+			exa.Free()
+		}
+
+		return float64(hits) / 20
+	}
+
+	param := NewParameter(C_SVC, LINEAR)
+	defer FreeParam(param)
+
+	base := recall(param)
+
+	param.SetClassWeights(map[int]float64{1: 10})
+	if param.object.nr_weight != 1 {
+		t.Error("Expected one class weight, got", param.object.nr_weight)
+	}
+
+	weighted := recall(param)
+	if weighted <= base {
+		t.Errorf("Expected weighted minority recall %f to exceed unweighted recall %f", weighted, base)
+	}
+
+	param.SetClassWeights(map[int]float64{})
+	if param.object.nr_weight != 0 || param.object.weight != nil {
+		t.Error("Expected an empty map to clear the class weights")
+	}
+}
+
+func TestGammaAuto(t *testing.T) {
+	labels, examples := readDenseData(t, "testdata/a1a", 123)
+	testLabels, testExamples := readDenseData(t, "testdata/a1a.t", 123)
+
+	prob, err := NewProblem(labels, examples)
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	accuracy := func(param *SvmParameter) float64 {
+		mdl, err := Train(*prob, *param)
+		if err != nil {
+			t.Fatal("Train error was non-nil", err)
+		}
+		defer FreeModel(mdl)
+
+		hits := 0
+		for i, ex := range testExamples[:2000] {
+			exa := NewExample(1, ex)
+			if v, _ := mdl.Predict(exa); v == testLabels[i] {
+				hits++
+			}
+			exa.Free()
+		}
+
+		return float64(hits) / 2000
+	}
+
+	param := NewParameter(C_SVC, RBF)
+	defer FreeParam(param)
+
+	zero := accuracy(param)
+
+	param.SetGammaAuto(123)
+	if param.object.gamma != 1.0/123 {
+		t.Error("Expected gamma of 1/123, got", param.object.gamma)
+	}
+
+	auto := accuracy(param)
+	if auto <= zero {
+		t.Errorf("Expected auto gamma accuracy %f to exceed gamma=0 accuracy %f", auto, zero)
+	}
+
+	param.SetGammaScale(10, 0.5)
+	if param.object.gamma != 0.2 {
+		t.Error("Expected gamma of 0.2, got", param.object.gamma)
+	}
+}
+
+func TestShrinkingEquivalent(t *testing.T) {
+	prob, param, _ := a1aProblem(t)
+	defer FreeProblem(prob)
+	defer FreeParam(param)
+
+	if !param.Shrinking() {
+		t.Error("Expected shrinking to be enabled by default")
+	}
+
+	shrunk, err := Train(*prob, *param)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(shrunk)
+
+	param.SetShrinking(false)
+	full, err := Train(*prob, *param)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(full)
+
+	if d := math.Abs(shrunk.Rho()[0] - full.Rho()[0]); d > 1e-2 {
+		t.Errorf("Rho differed by %f with and without shrinking", d)
+	}
+
+	_, examples := readDenseData(t, "testdata/a1a.t", 123)
+	differ := 0
+	for _, ex := range examples {
+		a, _ := shrunk.PredictDense(ex)
+		b, _ := full.PredictDense(ex)
+		if a != b {
+			differ++
+		}
+	}
+
+	if differ > len(examples)/100 {
+		t.Errorf("%d of %d predictions differed with and without shrinking", differ, len(examples))
+	}
+}
+
+// BenchmarkShrinking trains the same problem with and without the shrinking
+// heuristics, so the two sub-benchmarks' times show the tradeoff
+func BenchmarkShrinking(b *testing.B) {
+	prob, param, _ := a1aProblem(b)
+	defer FreeProblem(prob)
+	defer FreeParam(param)
+	SetQuiet(true)
+	defer SetQuiet(false)
+
+	for _, shrinking := range []bool{true, false} {
+		param.SetShrinking(shrinking)
+		b.Run(fmt.Sprintf("shrinking=%t", shrinking), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				mdl, err := Train(*prob, *param)
+				if err != nil {
+					b.Fatal("Train error was non-nil", err)
+				}
+				FreeModel(mdl)
+			}
+		})
+	}
+}
+
+func TestSetCacheSizeAuto(t *testing.T) {
+	param := NewParameter(C_SVC, RBF)
+	defer FreeParam(param)
+
+	mb, err := param.SetCacheSizeAuto()
+	if err != nil || mb < 100 || param.CacheSize() != mb {
+		t.Errorf("Unexpected automatic cache size %f, parameter holds %f", mb, param.CacheSize())
+	}
+}
+
+// BenchmarkCacheSize trains the same problem with a small and a large kernel
+// cache
+func BenchmarkCacheSize(b *testing.B) {
+	prob, param, _ := a1aProblem(b)
+	defer FreeProblem(prob)
+	defer FreeParam(param)
+	SetQuiet(true)
+	defer SetQuiet(false)
+
+	for _, mb := range []float64{40, 512} {
+		param.SetCacheSize(mb)
+		b.Run(fmt.Sprintf("cache=%gMB", mb), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				mdl, err := Train(*prob, *param)
+				if err != nil {
+					b.Fatal("Train error was non-nil", err)
+				}
+				FreeModel(mdl)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package libsvm
+
+// Params holds the settings of an SvmParameter as plain Go values, so they
+// can be kept in configuration files and encoded as JSON or YAML before any
+// C memory is allocated. The zero value is not usable for training; start
+// from DefaultParams. SvmType and Kernel hold the numeric values of the
+// C_SVC..NU_SVR and LINEAR..PRECOMPUTED constants.
+type Params struct {
+	SvmType      SvmType         `json:"svm_type"`
+	Kernel       KernelType      `json:"kernel"`
+	C            float64         `json:"c"`
+	Gamma        float64         `json:"gamma"`
+	Degree       int             `json:"degree"`
+	Coef0        float64         `json:"coef0"`
+	Nu           float64         `json:"nu"`
+	P            float64         `json:"p"`
+	Eps          float64         `json:"eps"`
+	CacheSize    float64         `json:"cache_size"`
+	Shrinking    bool            `json:"shrinking"`
+	Probability  bool            `json:"probability"`
+	ClassWeights map[int]float64 `json:"class_weights,omitempty"`
+}
+
+// DefaultParams returns the settings NewParameter starts from, the same
+// defaults svm-train uses
+func DefaultParams(svmType SvmType, kernel KernelType) Params {
+	return Params{
+		SvmType:   svmType,
+		Kernel:    kernel,
+		C:         1,
+		Degree:    3,
+		Nu:        0.5,
+		P:         0.1,
+		Eps:       1e-3,
+		CacheSize: 100,
+		Shrinking: true,
+	}
+}
+
+// ToSvmParameter allocates an SvmParameter holding the settings, returning
+// an error from Validate rather than a parameter LIBSVM would reject.
+// The parameter must be released with FreeParam.
+func (p Params) ToSvmParameter() (*SvmParameter, error) {
+	param := NewParameter(p.SvmType, p.Kernel)
+	param.SetC(p.C)
+	param.SetGamma(p.Gamma)
+	param.SetDegree(p.Degree)
+	param.SetCoef0(p.Coef0)
+	param.SetNu(p.Nu)
+	param.SetP(p.P)
+	param.SetEps(p.Eps)
+	param.SetCacheSize(p.CacheSize)
+	param.SetShrinking(p.Shrinking)
+	param.EnableProbability(p.Probability)
+	param.SetClassWeights(p.ClassWeights)
+
+	if err := param.Validate(); err != nil {
+		FreeParam(param)
+		return nil, err
+	}
+
+	return param, nil
+}
@@ -0,0 +1,52 @@
+package libsvm
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParamsJSON(t *testing.T) {
+	p := DefaultParams(C_SVC, RBF)
+	p.Gamma = 0.5
+	p.C = 4
+	p.ClassWeights = map[int]float64{1: 2}
+
+	raw, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal("Marshal error was non-nil", err)
+	}
+
+	var decoded Params
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatal("Unmarshal error was non-nil", err)
+	}
+
+	if !reflect.DeepEqual(decoded, p) {
+		t.Errorf("JSON round trip gave %+v, expected %+v", decoded, p)
+	}
+
+	param, err := decoded.ToSvmParameter()
+	if err != nil {
+		t.Fatal("ToSvmParameter error was non-nil", err)
+	}
+
+	if param.C() != 4 || param.Gamma() != 0.5 || !param.Shrinking() || param.Eps() != 1e-3 || param.object.nr_weight != 1 {
+		t.Error("Parameter does not hold the decoded settings")
+	}
+
+	mdl, prob := trainThreeClass(t, param)
+	defer FreeProblem(prob)
+	defer FreeModel(mdl)
+
+	if v, err := mdl.PredictDense([]float64{0.1, 4.9}); err != nil || v != 1 {
+		t.Error("Unexpected prediction from a model trained with decoded params", v, err)
+	}
+
+	bad := DefaultParams(NU_SVC, RBF)
+	bad.Nu = 2
+	if _, err := bad.ToSvmParameter(); !errors.Is(err, ErrInvalidParameter) {
+		t.Error("Expected an invalid parameter error, got", err)
+	}
+}
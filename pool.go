@@ -0,0 +1,136 @@
+package libsvm
+
+/*
+#include <svm.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// NodePool reuses fixed-capacity C node arrays, avoiding a C allocation and
+// free per example when predicting at high rates. It is safe for concurrent
+// use. A node drawn from the pool must not be used after it has been handed
+// back with Put or Free, since its memory may by then belong to another
+// caller; using it returns an ErrFreed error rather than reading that memory.
+type NodePool struct {
+	capacity int
+	buffers  sync.Pool
+}
+
+// nodeBuffer owns a C node array with room for a pool's capacity of features
+// plus the terminator. The array is freed by a finalizer once the buffer is
+// dropped, whether by the pool or by a caller that never returned its node.
+type nodeBuffer struct {
+	nodes []C.struct_svm_node
+	pool  *NodePool
+}
+
+// NewNodePool returns a pool of node arrays able to hold up to capacity
+// features each. An error is returned for a negative capacity.
+func NewNodePool(capacity int) (*NodePool, error) {
+	if capacity < 0 {
+		return nil, SvmError{Kind: ErrInvalidParameter, Message: fmt.Sprintf("invalid node pool capacity %d, must not be negative", capacity)}
+	}
+
+	return &NodePool{capacity: capacity}, nil
+}
+
+// Get returns a node with n features, initialised as a dense example of
+// zeroes indexed from 1; fill it with SetDense. Requests for more features
+// than the pool's capacity are served by an ordinary, unpooled node.
+// The node should be handed back with Put once it is no longer in use.
+// An error is returned for a negative n.
+func (p *NodePool) Get(n int) (*SvmNode, error) {
+	if n < 0 {
+		return nil, SvmError{Kind: ErrInvalidParameter, Message: fmt.Sprintf("invalid feature count %d when attempting to get a pooled node, must not be negative", n)}
+	}
+
+	var nodes []C.struct_svm_node
+	var buf *nodeBuffer
+	if n > p.capacity {
+		nodes = allocNodes(n + 1)
+	} else {
+		buf, _ = p.buffers.Get().(*nodeBuffer)
+		if buf == nil {
+			buf = &nodeBuffer{nodes: allocNodes(p.capacity + 1), pool: p}
+			runtime.SetFinalizer(buf, func(b *nodeBuffer) {
+				C.free(unsafe.Pointer(&b.nodes[0]))
+			})
+		}
+		nodes = buf.nodes[:n+1]
+	}
+
+	for i := 0; i < n; i++ {
+		nodes[i].index = C.int(i + 1)
+		nodes[i].value = 0
+	}
+	nodes[n].index = -1
+	nodes[n].value = 0
+
+	if buf == nil {
+		return newNode(nodes), nil
+	}
+
+	return &SvmNode{object: &nodes[0], length: n, buf: buf}, nil
+}
+
+// Put hands a node back to the pool. It is equivalent to node.Free, so nodes
+// that did not come from the pool are simply freed.
+func (p *NodePool) Put(node *SvmNode) {
+	node.Free()
+}
+
+// put returns a buffer to the pool for reuse
+func (p *NodePool) put(buf *nodeBuffer) {
+	p.buffers.Put(buf)
+}
+
+// SetDense overwrites the node's features with data, indexed consecutively
+// from startIndex. The data must have exactly as many features as the node,
+// and NaN and infinite values are rejected.
+func (node *SvmNode) SetDense(startIndex int, data []float64) error {
+	if node == nil || node.object == nil {
+		return SvmError{Kind: ErrNilNode, Message: "nil node when attempting to set node features"}
+	}
+
+	if len(data) != node.length {
+		return SvmError{Message: fmt.Sprintf("feature count %d does not match node length %d", len(data), node.length)}
+	}
+
+	for i, v := range data {
+		if err := checkFinite(startIndex+i, v); err != nil {
+			return err
+		}
+	}
+
+	nodes := unsafe.Slice(node.object, node.length)
+	for i, v := range data {
+		nodes[i].index = C.int(startIndex + i)
+		nodes[i].value = C.double(v)
+	}
+
+	runtime.KeepAlive(node)
+	return nil
+}
+
+// PredictPooled is like PredictDense, but builds the example in a node drawn
+// from pool rather than allocating a new one for each call
+func (mdl *SvmModel) PredictPooled(pool *NodePool, features []float64) (float64, error) {
+	node, err := pool.Get(len(features))
+	if err != nil {
+		return -1, err
+	}
+	defer pool.Put(node)
+
+	if err := node.SetDense(1, features); err != nil {
+		return -1, err
+	}
+
+	return mdl.Predict(node)
+}
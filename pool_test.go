@@ -0,0 +1,103 @@
+package libsvm
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// TestNodePool shares a pool between goroutines, so is best run with -race
+func TestNodePool(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	_, examples := readDenseData(t, "testdata/a1a.t", 123)
+	pool, err := NewNodePool(123)
+	if err != nil {
+		t.Fatal("NewNodePool error was non-nil", err)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < 400; i += 8 {
+				ex := examples[i]
+				want, _ := mdl.PredictDense(ex)
+				got, err := mdl.PredictPooled(pool, ex)
+				if err != nil || got != want {
+					t.Errorf("Pooled prediction was %f %v, expected %f", got, err, want)
+				}
+
+				node, err := pool.Get(len(ex))
+				if err != nil {
+					t.Error("Get error was non-nil", err)
+					return
+				}
+				if err := node.SetDense(1, ex); err != nil {
+					t.Error("SetDense error was non-nil", err)
+				}
+				if got, _ := mdl.Predict(node); got != want {
+					t.Errorf("Prediction from a pooled node was %f, expected %f", got, want)
+				}
+				pool.Put(node)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	node, _ := pool.Get(2)
+	if pairs := node.Pairs(); len(pairs) != 2 || pairs[1].Index != 2 || pairs[1].Value != 0 {
+		t.Error("Expected a fresh pooled node to be a dense zero example, got", pairs)
+	}
+	pool.Put(node)
+
+	if _, err := mdl.Predict(node); !errors.Is(err, ErrFreed) {
+		t.Error("Expected ErrFreed predicting with a node after Put, got", err)
+	}
+
+	big, _ := pool.Get(200)
+	if big.SetDense(1, make([]float64, 200)) != nil {
+		t.Error("Expected a node beyond the pool capacity to still be usable")
+	}
+	pool.Put(big)
+
+	if _, err := pool.Get(-1); !errors.Is(err, ErrInvalidParameter) {
+		t.Error("Expected an ErrInvalidParameter error for a negative feature count, got", err)
+	}
+
+	if _, err := NewNodePool(-1); !errors.Is(err, ErrInvalidParameter) {
+		t.Error("Expected an ErrInvalidParameter error for a negative capacity, got", err)
+	}
+}
+
+func BenchmarkPredictDense(b *testing.B) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		b.Fatal("Model load error was non-nil", err)
+	}
+	_, examples := readDenseData(b, "testdata/a1a.t", 123)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mdl.PredictDense(examples[i%len(examples)])
+	}
+}
+
+func BenchmarkPredictPooled(b *testing.B) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		b.Fatal("Model load error was non-nil", err)
+	}
+	_, examples := readDenseData(b, "testdata/a1a.t", 123)
+	pool, _ := NewNodePool(123)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mdl.PredictPooled(pool, examples[i%len(examples)])
+	}
+}
@@ -0,0 +1,621 @@
+package libsvm
+
+/*
+#include <svm.h>
+#include <stdlib.h>
+
+static void predict_batch(const struct svm_model *model, struct svm_node **nodes, int n, double *out) {
+	int i;
+	for (i = 0; i < n; i++) {
+		out[i] = svm_predict(model, nodes[i]);
+	}
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// PredictVotes predicts the node with a classification model and returns the
+// one-vs-one votes behind the prediction. Each pairwise decision value votes
+// for the first class of its pair when positive and the second otherwise, as
+// LIBSVM does, so the votes always total nr_class*(nr_class-1)/2.
+// The returned label is the class with the most votes, ties going to the
+// class that comes first in Labels.
+func (mdl *SvmModel) PredictVotes(node *SvmNode) (float64, map[int]int, error) {
+	if mdl != nil {
+		mdl.mu.RLock()
+		defer mdl.mu.RUnlock()
+	}
+
+	if err := checkPredict(mdl, node, "predict votes using an svm model"); err != nil {
+		return -1, nil, err
+	}
+
+	if t := mdl.svmType(); t != C_SVC && t != NU_SVC {
+		return -1, nil, SvmError{Kind: ErrWrongModelType, Message: "votes are only available for classification models, not " + t.String()}
+	}
+
+	_, decValues, err := mdl.predictValues(node)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	labels := mdl.labels()
+	counts := make([]int, len(labels))
+	p := 0
+	for i := range labels {
+		for j := i + 1; j < len(labels); j++ {
+			if decValues[p] > 0 {
+				counts[i]++
+			} else {
+				counts[j]++
+			}
+			p++
+		}
+	}
+
+	best := 0
+	votes := make(map[int]int, len(labels))
+	for i, label := range labels {
+		votes[label] = counts[i]
+		if counts[i] > counts[best] {
+			best = i
+		}
+	}
+
+	return float64(labels[best]), votes, nil
+}
+
+// PredictWithReject predicts the node with a model trained with probability
+// estimates, abstaining when unsure: rejected is true when the probability
+// of the predicted class is below minProbability. The predicted label is
+// returned either way, so callers can still log what the model would have
+// chosen. Only classification models have class probabilities to compare.
+func (mdl *SvmModel) PredictWithReject(node *SvmNode, minProbability float64) (float64, bool, error) {
+	if mdl != nil {
+		mdl.mu.RLock()
+		defer mdl.mu.RUnlock()
+	}
+
+	if err := checkModel(mdl, "predict with reject using an svm model"); err != nil {
+		return -1, false, err
+	}
+
+	if err := checkClassification(mdl, "predict with reject"); err != nil {
+		return -1, false, err
+	}
+
+	if minProbability < 0 || minProbability > 1 {
+		return -1, false, SvmError{Kind: ErrInvalidParameter, Message: fmt.Sprintf("invalid minimum probability %g: must be between 0 and 1", minProbability)}
+	}
+
+	label, probs, err := mdl.predictProbability(node)
+	if err != nil {
+		return -1, false, err
+	}
+
+	top := 0.0
+	for _, p := range probs {
+		if p > top {
+			top = p
+		}
+	}
+
+	return label, top < minProbability, nil
+}
+
+// PredictChecked is like Predict, but returns an error rather than a NaN or
+// infinite prediction, which LIBSVM produces without complaint from
+// non-finite features or a corrupt model.
+func (mdl *SvmModel) PredictChecked(node *SvmNode) (float64, error) {
+	label, err := mdl.Predict(node)
+	if err != nil {
+		return label, err
+	}
+
+	if math.IsNaN(label) || math.IsInf(label, 0) {
+		return label, SvmError{Message: fmt.Sprintf("prediction %g is not finite, check the example for NaN or infinite features", label)}
+	}
+
+	return label, nil
+}
+
+// LabelProbability is a class label and its estimated probability
+type LabelProbability struct {
+	Label       float64
+	Probability float64
+}
+
+// PredictTopK returns the k most probable classes of the node, most probable
+// first, using a model trained with probability estimates. Classes of equal
+// probability keep the order of Labels. An error is returned if k is not
+// between 1 and the number of classes, or for non-classification models.
+func (mdl *SvmModel) PredictTopK(node *SvmNode, k int) ([]LabelProbability, error) {
+	if mdl != nil {
+		mdl.mu.RLock()
+		defer mdl.mu.RUnlock()
+	}
+
+	if err := checkModel(mdl, "predict the top classes using an svm model"); err != nil {
+		return nil, err
+	}
+
+	if err := checkClassification(mdl, "predict the top classes"); err != nil {
+		return nil, err
+	}
+
+	if k < 1 || k > mdl.nrClass() {
+		return nil, SvmError{Kind: ErrInvalidParameter, Message: fmt.Sprintf("invalid k %d, must be between 1 and the number of classes %d", k, mdl.nrClass())}
+	}
+
+	return mdl.topK(node, k)
+}
+
+// topK is PredictTopK, without the argument checks, for callers holding the
+// lock
+func (mdl *SvmModel) topK(node *SvmNode, k int) ([]LabelProbability, error) {
+	_, probs, err := mdl.predictProbability(node)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := mdl.labels()
+	res := make([]LabelProbability, len(probs))
+	for i, p := range probs {
+		res[i] = LabelProbability{Label: float64(labels[i]), Probability: p}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Probability > res[j].Probability
+	})
+
+	return res[:k], nil
+}
+
+// PredictAboveThreshold returns every class whose estimated probability is
+// at least threshold, most probable first, for pseudo-multilabel decisions
+// from a model trained with probability estimates. The result is empty when
+// no class reaches the threshold. Non-classification models are rejected.
+func (mdl *SvmModel) PredictAboveThreshold(node *SvmNode, threshold float64) ([]LabelProbability, error) {
+	if mdl != nil {
+		mdl.mu.RLock()
+		defer mdl.mu.RUnlock()
+	}
+
+	if err := checkModel(mdl, "predict classes above a threshold using an svm model"); err != nil {
+		return nil, err
+	}
+
+	if err := checkClassification(mdl, "predict classes above a threshold"); err != nil {
+		return nil, err
+	}
+
+	all, err := mdl.topK(node, mdl.nrClass())
+	if err != nil {
+		return nil, err
+	}
+
+	n := 0
+	for n < len(all) && all[n].Probability >= threshold {
+		n++
+	}
+
+	return all[:n], nil
+}
+
+// PredictMatrix predicts every row of X, a dense matrix indexed from 1 as in
+// PredictDense, building and freeing the nodes internally. Prediction only
+// reads the model, so with workers above 1 the rows are split into that many
+// contiguous ranges predicted concurrently; otherwise they are predicted in
+// order on the calling goroutine. The first failing row, if any, is reported.
+func (mdl *SvmModel) PredictMatrix(X [][]float64, workers int) ([]float64, error) {
+	if err := checkModel(mdl, "predict a matrix using an svm model"); err != nil {
+		return nil, err
+	}
+
+	res := make([]float64, len(X))
+	if workers > len(X) {
+		workers = len(X)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	errs := make([]error, workers)
+	predictRange := func(w, from, to int) {
+		for i := from; i < to; i++ {
+			v, err := mdl.PredictDense(X[i])
+			if err != nil {
+				errs[w] = wrapError(fmt.Sprintf("row %d", i), err)
+				return
+			}
+			res[i] = v
+		}
+	}
+
+	if workers == 1 {
+		predictRange(0, 0, len(X))
+	} else {
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				predictRange(w, w*len(X)/workers, (w+1)*len(X)/workers)
+			}(w)
+		}
+		wg.Wait()
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// Predict will use the model to predict the next values based on the inputs in the SvmNode object
+func (mdl *SvmModel) Predict(node *SvmNode) (float64, error) {
+	if mdl != nil {
+		mdl.mu.RLock()
+		defer mdl.mu.RUnlock()
+	}
+
+	return mdl.predict(node)
+}
+
+// predict is Predict for callers holding the lock
+func (mdl *SvmModel) predict(node *SvmNode) (float64, error) {
+	if err := checkPredict(mdl, node, "predict using an svm model"); err != nil {
+		return -1, err
+	}
+
+	defer runtime.KeepAlive(node)
+	defer runtime.KeepAlive(mdl)
+	return float64(C.svm_predict(mdl.object, node.object)), nil
+}
+
+// PredictTimed is like Predict, but also returns the wall-clock time spent
+// in the svm_predict call itself, excluding node construction and the
+// checks made before it, for latency monitoring
+func (mdl *SvmModel) PredictTimed(node *SvmNode) (float64, time.Duration, error) {
+	if mdl != nil {
+		mdl.mu.RLock()
+		defer mdl.mu.RUnlock()
+	}
+
+	if err := checkPredict(mdl, node, "predict using an svm model"); err != nil {
+		return -1, 0, err
+	}
+
+	start := time.Now()
+	label := C.svm_predict(mdl.object, node.object)
+	elapsed := time.Since(start)
+	runtime.KeepAlive(mdl)
+	runtime.KeepAlive(node)
+
+	return float64(label), elapsed, nil
+}
+
+// PredictStrict is like Predict, but returns an error if the node uses a
+// feature index beyond the largest one seen during training, which usually
+// means the prediction data no longer matches the training data's schema.
+// For loaded models the largest index is taken from the support vectors,
+// since the training data is not stored in the model file.
+func (mdl *SvmModel) PredictStrict(node *SvmNode) (float64, error) {
+	if mdl != nil {
+		mdl.mu.RLock()
+		defer mdl.mu.RUnlock()
+	}
+
+	if err := checkPredict(mdl, node, "predict using an svm model"); err != nil {
+		return -1, err
+	}
+
+	for _, p := range node.Pairs() {
+		if p.Index > mdl.maxIndex {
+			return -1, SvmError{Kind: ErrInvalidInput, Message: fmt.Sprintf("feature index %d exceeds the largest index %d seen during training", p.Index, mdl.maxIndex)}
+		}
+	}
+
+	return mdl.predict(node)
+}
+
+// PredictDense predicts a dense feature vector, indexed from 1, without the
+// caller having to build and free an SvmNode. NaN and infinite features are
+// rejected with an error naming their index. An empty or nil vector is the
+// all-zero example, which LIBSVM handles like any other: for a LINEAR kernel
+// its decision value is just the bias -rho.
+func (mdl *SvmModel) PredictDense(features []float64) (float64, error) {
+	node, err := NewDenseExample(1, features)
+	if err != nil {
+		return -1, err
+	}
+	defer node.Free()
+
+	return mdl.Predict(node)
+}
+
+// PredictMap predicts a sparse example held as a map from feature index to
+// value, as returned by many feature stores. Indices must be positive; zero
+// values may be left out, as in LIBSVM data files.
+func (mdl *SvmModel) PredictMap(features map[int]float64) (float64, error) {
+	indices := make([]int, 0, len(features))
+	for idx := range features {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+
+	values := make([]float64, len(indices))
+	for i, idx := range indices {
+		values[i] = features[idx]
+	}
+
+	node, err := NewSparseExample(indices, values)
+	if err != nil {
+		return -1, err
+	}
+	defer node.Free()
+
+	return mdl.Predict(node)
+}
+
+// PredictBatch will use the model to predict a value for every node, making a
+// single cgo call for the whole batch rather than one per node
+func (mdl *SvmModel) PredictBatch(nodes []*SvmNode) ([]float64, error) {
+	if mdl != nil {
+		mdl.mu.RLock()
+		defer mdl.mu.RUnlock()
+	}
+
+	if err := checkModel(mdl, "predict a batch using an svm model"); err != nil {
+		return nil, err
+	}
+
+	if len(nodes) == 0 {
+		return []float64{}, nil
+	}
+
+	ptrs := make([]*C.struct_svm_node, len(nodes))
+	for i, node := range nodes {
+		if node == nil || node.object == nil {
+			return nil, SvmError{Kind: ErrNilNode, Message: fmt.Sprintf("nil node at index %d when attempting to predict a batch using an svm model", i)}
+		}
+
+		if err := checkLayout(mdl, node); err != nil {
+			return nil, wrapError(fmt.Sprintf("node at index %d", i), err)
+		}
+		ptrs[i] = node.object
+	}
+
+	out := make([]C.double, len(nodes))
+	C.predict_batch(mdl.object, &ptrs[0], C.int(len(nodes)), &out[0])
+	runtime.KeepAlive(mdl)
+	runtime.KeepAlive(nodes)
+
+	res := make([]float64, len(out))
+	for i, v := range out {
+		res[i] = float64(v)
+	}
+
+	return res, nil
+}
+
+// PredictProbability will use a model trained with probability estimates to
+// predict the label of the node, along with the probability of each class.
+// The probabilities are ordered the same way as the labels returned by Labels.
+func (mdl *SvmModel) PredictProbability(node *SvmNode) (float64, []float64, error) {
+	if mdl != nil {
+		mdl.mu.RLock()
+		defer mdl.mu.RUnlock()
+	}
+
+	return mdl.predictProbability(node)
+}
+
+// predictProbability is PredictProbability for callers holding the lock
+func (mdl *SvmModel) predictProbability(node *SvmNode) (float64, []float64, error) {
+	if err := checkPredict(mdl, node, "predict probabilities using an svm model"); err != nil {
+		return -1, nil, err
+	}
+
+	if err := checkNotRegression(mdl, "predict probabilities"); err != nil {
+		return -1, nil, err
+	}
+
+	if C.svm_check_probability_model(mdl.object) == 0 {
+		return -1, nil, SvmError{Kind: ErrNoProbability, Message: "model does not contain probability estimates when attempting to predict probabilities"}
+	}
+
+	estimates := make([]C.double, int(C.svm_get_nr_class(mdl.object)))
+	label := C.svm_predict_probability(mdl.object, node.object, &estimates[0])
+	runtime.KeepAlive(mdl)
+	runtime.KeepAlive(node)
+
+	probs := make([]float64, len(estimates))
+	for i, v := range estimates {
+		probs[i] = float64(v)
+	}
+
+	return float64(label), probs, nil
+}
+
+// PredictValues will use the model to predict the node, also returning the
+// decision values. Classification models return one value per pair of
+// classes, ordered (0,1), (0,2), ..., (1,2), ... by the class order of Labels,
+// the same order as Rho. Regression and one-class models return a single value.
+func (mdl *SvmModel) PredictValues(node *SvmNode) (float64, []float64, error) {
+	if mdl != nil {
+		mdl.mu.RLock()
+		defer mdl.mu.RUnlock()
+	}
+
+	return mdl.predictValues(node)
+}
+
+// predictValues is PredictValues for callers holding the lock
+func (mdl *SvmModel) predictValues(node *SvmNode) (float64, []float64, error) {
+	if err := checkPredict(mdl, node, "predict decision values using an svm model"); err != nil {
+		return -1, nil, err
+	}
+
+	decValues := make([]C.double, decisionValueCount(mdl))
+	label := C.svm_predict_values(mdl.object, node.object, &decValues[0])
+	runtime.KeepAlive(mdl)
+	runtime.KeepAlive(node)
+
+	values := make([]float64, len(decValues))
+	for i, v := range decValues {
+		values[i] = float64(v)
+	}
+
+	return float64(label), values, nil
+}
+
+// PredictValuesInto is like PredictValues, but writes the decision values
+// into the start of buf instead of allocating, so hot loops can reuse one
+// buffer. An error is returned if buf is shorter than the number of decision
+// values, nr_class*(nr_class-1)/2 for classification and 1 otherwise.
+func (mdl *SvmModel) PredictValuesInto(node *SvmNode, buf []float64) (float64, error) {
+	if mdl != nil {
+		mdl.mu.RLock()
+		defer mdl.mu.RUnlock()
+	}
+
+	if err := checkPredict(mdl, node, "predict decision values using an svm model"); err != nil {
+		return -1, err
+	}
+
+	if n := decisionValueCount(mdl); len(buf) < n {
+		return -1, SvmError{Message: fmt.Sprintf("buffer of length %d is too small for %d decision values", len(buf), n)}
+	}
+
+	label := C.svm_predict_values(mdl.object, node.object, (*C.double)(unsafe.Pointer(&buf[0])))
+	runtime.KeepAlive(mdl)
+	runtime.KeepAlive(node)
+
+	return float64(label), nil
+}
+
+// PredictProbabilityInto is like PredictProbability, but writes the class
+// probabilities into the start of buf instead of allocating. An error is
+// returned if buf is shorter than the number of classes.
+func (mdl *SvmModel) PredictProbabilityInto(node *SvmNode, buf []float64) (float64, error) {
+	if mdl != nil {
+		mdl.mu.RLock()
+		defer mdl.mu.RUnlock()
+	}
+
+	if err := checkPredict(mdl, node, "predict probabilities using an svm model"); err != nil {
+		return -1, err
+	}
+
+	if err := checkNotRegression(mdl, "predict probabilities"); err != nil {
+		return -1, err
+	}
+
+	if C.svm_check_probability_model(mdl.object) == 0 {
+		return -1, SvmError{Kind: ErrNoProbability, Message: "model does not contain probability estimates when attempting to predict probabilities"}
+	}
+
+	if n := int(C.svm_get_nr_class(mdl.object)); len(buf) < n {
+		return -1, SvmError{Message: fmt.Sprintf("buffer of length %d is too small for %d class probabilities", len(buf), n)}
+	}
+
+	label := C.svm_predict_probability(mdl.object, node.object, (*C.double)(unsafe.Pointer(&buf[0])))
+	runtime.KeepAlive(mdl)
+	runtime.KeepAlive(node)
+
+	return float64(label), nil
+}
+
+// checkModel ensures the model is usable before handing it to LIBSVM
+func checkModel(mdl *SvmModel, action string) error {
+	if mdl == nil {
+		return SvmError{Kind: ErrNilModel, Message: "nil model when attempting to " + action}
+	}
+
+	if atomic.LoadInt32(&mdl.freed) != 0 {
+		return SvmError{Kind: ErrFreed, Message: "svm model has been freed when attempting to " + action}
+	}
+
+	if mdl.object == nil {
+		return SvmError{Kind: ErrNilModel, Message: "model object's internal svm_model pointer is nil when attempting to " + action}
+	}
+
+	return nil
+}
+
+// checkNotRegression ensures a usable model predicts classes, since
+// regression models have no class probabilities or labels
+func checkNotRegression(mdl *SvmModel, action string) error {
+	if t := SvmType(mdl.object.param.svm_type); t == EPSILON_SVR || t == NU_SVR {
+		return SvmError{Kind: ErrWrongModelType, Message: fmt.Sprintf("%s models cannot %s, use Predict and SvrProbability instead", t, action)}
+	}
+
+	return nil
+}
+
+// checkClassification ensures a usable model is a C_SVC or NU_SVC model,
+// the only types with class labels
+func checkClassification(mdl *SvmModel, action string) error {
+	if t := SvmType(mdl.object.param.svm_type); t != C_SVC && t != NU_SVC {
+		return SvmError{Kind: ErrWrongModelType, Message: fmt.Sprintf("%s models cannot %s, only classification models can", t, action)}
+	}
+
+	return nil
+}
+
+// checkPredict ensures both the model and node are usable before handing them to LIBSVM
+func checkPredict(mdl *SvmModel, node *SvmNode, action string) error {
+	if err := checkModel(mdl, action); err != nil {
+		return err
+	}
+
+	if node == nil {
+		return SvmError{Kind: ErrNilNode, Message: "nil node when attempting to " + action}
+	}
+
+	if atomic.LoadInt32(&node.freed) != 0 {
+		return SvmError{Kind: ErrFreed, Message: "svm node has been freed when attempting to " + action}
+	}
+
+	if node.object == nil {
+		return SvmError{Kind: ErrNilNode, Message: "node object's internal svm_node pointer is nil when attempting to " + action}
+	}
+
+	return checkLayout(mdl, node)
+}
+
+// checkLayout ensures examples for a PRECOMPUTED kernel model hold a serial
+// number at index 0. LIBSVM reads K(x, x_j) as the value at index j of such
+// examples, so an ordinary example would otherwise be silently misread.
+func checkLayout(mdl *SvmModel, node *SvmNode) error {
+	if KernelType(mdl.object.param.kernel_type) != PRECOMPUTED || node.object.index == 0 {
+		return nil
+	}
+
+	return SvmError{Kind: ErrInvalidInput, Message: "model uses the PRECOMPUTED kernel, so examples must be built with NewPrecomputedExample, which stores the serial number at index 0"}
+}
+
+// decisionValueCount returns the number of decision values LIBSVM writes for the model
+func decisionValueCount(mdl *SvmModel) int {
+	switch SvmType(mdl.object.param.svm_type) {
+	case ONE_CLASS, EPSILON_SVR, NU_SVR:
+		return 1
+	}
+
+	nrClass := int(mdl.object.nr_class)
+	return nrClass * (nrClass - 1) / 2
+}
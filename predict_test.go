@@ -0,0 +1,634 @@
+package libsvm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPredictVotes(t *testing.T) {
+	mdl, prob := trainThreeClass(t, NewParameter(C_SVC, LINEAR))
+	defer FreeProblem(prob)
+	defer FreeModel(mdl)
+
+	exa := NewExample(1, []float64{5.2, 0.1})
+	defer exa.Free()
+
+	label, votes, err := mdl.PredictVotes(exa)
+	if err != nil {
+		t.Fatal("PredictVotes error was non-nil", err)
+	}
+
+	want, _ := mdl.Predict(exa)
+	if label != want || label != 2 {
+		t.Errorf("Votes chose %f, Predict chose %f", label, want)
+	}
+
+	total := 0
+	for _, v := range votes {
+		total += v
+	}
+
+	if len(votes) != 3 || total != 3 || votes[2] != 2 {
+		t.Error("Unexpected votes", votes)
+	}
+
+	svr, svrProb := trainProbabilitySvr(t)
+	defer FreeProblem(svrProb)
+	defer FreeModel(svr)
+
+	if _, _, err := svr.PredictVotes(exa); !errors.Is(err, ErrWrongModelType) {
+		t.Error("Expected ErrWrongModelType predicting votes with a regression model, got", err)
+	}
+}
+
+func TestPredictWithReject(t *testing.T) {
+	param := NewParameter(C_SVC, RBF)
+	param.SetGammaAuto(2)
+	param.EnableProbability(true)
+	mdl, prob := trainThreeClass(t, param)
+	defer FreeProblem(prob)
+	defer FreeModel(mdl)
+
+	confident := NewExample(1, []float64{0.2, 4.8})
+	defer confident.Free()
+
+	label, rejected, err := mdl.PredictWithReject(confident, 0.7)
+	if err != nil {
+		t.Fatal("PredictWithReject error was non-nil", err)
+	}
+
+	if label != 1 || rejected {
+		t.Errorf("Expected a confident prediction of 1, got %f rejected %t", label, rejected)
+	}
+
+	// equidistant from the centres of classes 1 and 2
+	ambiguous := NewExample(1, []float64{2.5, 2.5})
+	defer ambiguous.Free()
+
+	if _, rejected, err := mdl.PredictWithReject(ambiguous, 0.7); err != nil || !rejected {
+		t.Error("Expected an ambiguous input to be rejected", err)
+	}
+
+	if _, _, err := mdl.PredictWithReject(confident, 1.5); err == nil {
+		t.Error("Expected an error for a minimum probability above 1")
+	}
+
+	plain, plainProb := trainThreeClass(t, NewParameter(C_SVC, LINEAR))
+	defer FreeProblem(plainProb)
+	defer FreeModel(plain)
+
+	if _, _, err := plain.PredictWithReject(confident, 0.5); !errors.Is(err, ErrNoProbability) {
+		t.Error("Expected ErrNoProbability without probability estimates, got", err)
+	}
+
+	svr, svrProb := trainProbabilitySvr(t)
+	defer FreeProblem(svrProb)
+	defer FreeModel(svr)
+
+	exa := NewExample(1, []float64{1.5})
+	defer exa.Free()
+
+	if _, _, err := svr.PredictWithReject(exa, 0.5); !errors.Is(err, ErrWrongModelType) {
+		t.Error("Expected a wrong model type error for an EPSILON_SVR model, got", err)
+	}
+}
+
+func TestPredictChecked(t *testing.T) {
+	prob, err := NewProblem([]float64{1, 2, 3, 4, 5}, [][]float64{{1}, {2}, {3}, {4}, {5}})
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	param := NewParameter(EPSILON_SVR, LINEAR)
+	defer FreeParam(param)
+
+	mdl, err := Train(*prob, *param)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	finite := NewExample(1, []float64{2.5})
+	defer finite.Free()
+
+	if v, err := mdl.PredictChecked(finite); err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		t.Error("Expected a finite prediction, got", v, err)
+	}
+
+	// NewExample does not validate, so an infinite feature reaches LIBSVM
+	infinite := NewExample(1, []float64{math.Inf(1)})
+	defer infinite.Free()
+
+	if _, err := mdl.PredictChecked(infinite); err == nil {
+		t.Error("Expected an error for an infinite prediction")
+	}
+}
+
+func TestPredictTopK(t *testing.T) {
+	param := NewParameter(C_SVC, RBF)
+	param.SetGammaAuto(2)
+	param.EnableProbability(true)
+	mdl, prob := trainThreeClass(t, param)
+	defer FreeProblem(prob)
+	defer FreeModel(mdl)
+
+	exa := NewExample(1, []float64{4.8, 0.3})
+	defer exa.Free()
+
+	top, err := mdl.PredictTopK(exa, 3)
+	if err != nil {
+		t.Fatal("PredictTopK error was non-nil", err)
+	}
+
+	label, _, _ := mdl.PredictProbability(exa)
+	if len(top) != 3 || top[0].Label != label {
+		t.Errorf("Expected the top class to be %f, got %+v", label, top)
+	}
+
+	for i := 1; i < len(top); i++ {
+		if top[i].Probability > top[i-1].Probability {
+			t.Error("Expected classes sorted by descending probability, got", top)
+		}
+	}
+
+	for _, k := range []int{0, 4} {
+		if _, err := mdl.PredictTopK(exa, k); !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("Expected an ErrInvalidParameter error for k %d, got %v", k, err)
+		}
+	}
+
+	svr, svrProb := trainProbabilitySvr(t)
+	defer FreeProblem(svrProb)
+	defer FreeModel(svr)
+
+	if _, err := svr.PredictTopK(exa, 1); !errors.Is(err, ErrWrongModelType) {
+		t.Error("Expected a wrong model type error for an EPSILON_SVR model, got", err)
+	}
+
+	plain, plainProb := trainThreeClass(t, NewParameter(C_SVC, LINEAR))
+	defer FreeProblem(plainProb)
+	defer FreeModel(plain)
+
+	if _, err := plain.PredictTopK(exa, 1); !errors.Is(err, ErrNoProbability) {
+		t.Error("Expected ErrNoProbability without probability estimates, got", err)
+	}
+}
+
+func TestPredictAboveThreshold(t *testing.T) {
+	param := NewParameter(C_SVC, RBF)
+	param.SetGammaAuto(2)
+	param.EnableProbability(true)
+	mdl, prob := trainThreeClass(t, param)
+	defer FreeProblem(prob)
+	defer FreeModel(mdl)
+
+	// equidistant from the centres of classes 1 and 2, far from class 3
+	exa := NewExample(1, []float64{2.5, 2.5})
+	defer exa.Free()
+
+	above, err := mdl.PredictAboveThreshold(exa, 0.25)
+	if err != nil {
+		t.Fatal("PredictAboveThreshold error was non-nil", err)
+	}
+
+	if len(above) != 2 || above[0].Label == 3 || above[1].Label == 3 {
+		t.Error("Expected classes 1 and 2 above the threshold, got", above)
+	}
+
+	if none, err := mdl.PredictAboveThreshold(exa, 1.01); err != nil || len(none) != 0 {
+		t.Error("Expected no classes above an unreachable threshold, got", none, err)
+	}
+
+	svr, svrProb := trainProbabilitySvr(t)
+	defer FreeProblem(svrProb)
+	defer FreeModel(svr)
+
+	if _, err := svr.PredictAboveThreshold(exa, 0.5); !errors.Is(err, ErrWrongModelType) {
+		t.Error("Expected a wrong model type error for an EPSILON_SVR model, got", err)
+	}
+}
+
+func TestPredictMatrix(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	_, examples := readDenseData(t, "testdata/a1a.t", 123)
+	examples = examples[:500]
+
+	serial, err := mdl.PredictMatrix(examples, 1)
+	if err != nil {
+		t.Fatal("PredictMatrix error was non-nil", err)
+	}
+
+	parallel, err := mdl.PredictMatrix(examples, 7)
+	if err != nil {
+		t.Fatal("PredictMatrix error was non-nil", err)
+	}
+
+	if len(serial) != len(examples) || len(parallel) != len(examples) {
+		t.Fatalf("Expected %d predictions, got %d and %d", len(examples), len(serial), len(parallel))
+	}
+
+	for i, exa := range examples {
+		want, _ := mdl.PredictDense(exa)
+		if serial[i] != want || parallel[i] != want {
+			t.Fatalf("Row %d predicted as %f serially and %f in parallel, expected %f", i, serial[i], parallel[i], want)
+		}
+	}
+
+	bad := [][]float64{examples[0], {math.NaN()}, examples[1]}
+	if _, err := mdl.PredictMatrix(bad, 2); !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "row 1") {
+		t.Error("Expected an ErrInvalidInput error naming row 1, got", err)
+	}
+
+	if res, err := mdl.PredictMatrix(nil, 4); err != nil || len(res) != 0 {
+		t.Error("Expected no predictions for an empty matrix", res, err)
+	}
+}
+
+func benchmarkPredictMatrix(b *testing.B, workers int) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		b.Fatal("Model load error was non-nil", err)
+	}
+
+	_, examples := readDenseData(b, "testdata/a1a.t", 123)
+	X := make([][]float64, 100000)
+	for i := range X {
+		X[i] = examples[i%len(examples)]
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mdl.PredictMatrix(X, workers)
+	}
+}
+
+func BenchmarkPredictMatrixSerial(b *testing.B) {
+	benchmarkPredictMatrix(b, 1)
+}
+
+func BenchmarkPredictMatrixParallel(b *testing.B) {
+	benchmarkPredictMatrix(b, runtime.NumCPU())
+}
+
+func TestPredictProbabilityWithoutEstimates(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	exa := NewExample(1, []float64{1, 0, 0, 0, 1, 1, 1})
+	if _, _, err := mdl.PredictProbability(exa); err == nil {
+		t.Error("Expected an error predicting probabilities with a non-probability model")
+	}
+}
+
+func TestPredictProbabilityRegression(t *testing.T) {
+	mdl, prob := trainProbabilitySvr(t)
+	defer FreeProblem(prob)
+	defer FreeModel(mdl)
+
+	exa := NewExample(1, []float64{1.5})
+	defer exa.Free()
+
+	if _, _, err := mdl.PredictProbability(exa); !errors.Is(err, ErrWrongModelType) {
+		t.Error("Expected a wrong model type error for an EPSILON_SVR model, got", err)
+	}
+
+	if _, err := mdl.PredictProbabilityInto(exa, make([]float64, 2)); !errors.Is(err, ErrWrongModelType) {
+		t.Error("Expected a wrong model type error from PredictProbabilityInto, got", err)
+	}
+}
+
+func TestPredictStrict(t *testing.T) {
+	prob, err := NewProblem([]float64{1, -1}, [][]float64{{1, 0, 1}, {-1, 0, -1}})
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	param := NewParameter(C_SVC, LINEAR)
+	defer FreeParam(param)
+
+	mdl, err := Train(*prob, *param)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	inRange := NewExample(1, []float64{1, 1, 1})
+	defer inRange.Free()
+	if _, err := mdl.PredictStrict(inRange); err != nil {
+		t.Error("PredictStrict error was non-nil for an in range example", err)
+	}
+
+	outOfRange, _ := NewSparseExample([]int{1, 4}, []float64{1, 1})
+	defer outOfRange.Free()
+	if _, err := mdl.PredictStrict(outOfRange); !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "index 4") {
+		t.Error("Expected an error for an out of range index, got", err)
+	}
+}
+
+func TestPredictTimed(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	_, examples := readDenseData(t, "testdata/a1a.t", 123)
+	for _, exa := range examples[:100] {
+		node := NewExample(1, exa)
+		want, _ := mdl.Predict(node)
+		got, elapsed, err := mdl.PredictTimed(node)
+		node.Free()
+
+		if err != nil {
+			t.Fatal("PredictTimed error was non-nil", err)
+		}
+
+		if got != want || elapsed < 0 {
+			t.Fatalf("PredictTimed gave %f in %s, expected %f", got, elapsed, want)
+		}
+	}
+
+	if _, _, err := mdl.PredictTimed(nil); !errors.Is(err, ErrNilNode) {
+		t.Error("Expected a nil node error, got", err)
+	}
+}
+
+func TestPredictDense(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	_, examples := readDenseData(t, "testdata/a1a", 123)
+	for i := 0; i < 1000; i++ {
+		ex := examples[i%len(examples)]
+		got, err := mdl.PredictDense(ex)
+		if err != nil {
+			t.Fatal("PredictDense error was non-nil", err)
+		}
+
+		exa := NewExample(1, ex)
+		want, _ := mdl.Predict(exa)
+		exa.Free()
+
+		if got != want {
+			t.Fatalf("PredictDense returned %f, expected %f", got, want)
+		}
+	}
+
+	// The node array lives in C memory and is freed before returning, so the
+	// Go allocations per call are a small constant whatever the vector length
+	short := testing.AllocsPerRun(1000, func() { mdl.PredictDense(examples[0][:2]) })
+	long := testing.AllocsPerRun(1000, func() { mdl.PredictDense(examples[0]) })
+	if short != long || long > 4 {
+		t.Errorf("Expected a few allocations per prediction whatever the length, got %.1f for 2 features and %.1f for %d", short, long, len(examples[0]))
+	}
+}
+
+func TestPredictDenseEmpty(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	empty, err := mdl.PredictDense(nil)
+	if err != nil {
+		t.Fatal("PredictDense error was non-nil for a nil vector", err)
+	}
+
+	zeroes, err := mdl.PredictDense(make([]float64, 123))
+	if err != nil {
+		t.Fatal("PredictDense error was non-nil", err)
+	}
+
+	again, _ := mdl.PredictDense([]float64{})
+	if math.IsNaN(empty) || math.IsInf(empty, 0) || empty != zeroes || empty != again {
+		t.Errorf("Expected the empty vector to predict like all zeroes, got %f, %f and %f", empty, again, zeroes)
+	}
+
+	_, values, err := mdl.PredictValues(NewExample(1, nil))
+	if err != nil {
+		t.Fatal("PredictValues error was non-nil", err)
+	}
+
+	// every RBF kernel value is exp(-gamma*|sv|^2), so the decision value is
+	// finite and the same on every call
+	if _, again, _ := mdl.PredictValues(NewExample(1, nil)); math.IsNaN(values[0]) || values[0] != again[0] {
+		t.Error("Expected a finite, deterministic decision value, got", values, again)
+	}
+}
+
+func TestPredictMap(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	_, examples := readDenseData(t, "testdata/a1a", 123)
+	for _, ex := range examples[:200] {
+		features := make(map[int]float64)
+		var indices []int
+		var values []float64
+		for j, v := range ex {
+			if v != 0 {
+				features[j+1] = v
+				indices = append(indices, j+1)
+				values = append(values, v)
+			}
+		}
+
+		got, err := mdl.PredictMap(features)
+		if err != nil {
+			t.Fatal("PredictMap error was non-nil", err)
+		}
+
+		exa, err := NewSparseExample(indices, values)
+		if err != nil {
+			t.Fatal("NewSparseExample error was non-nil", err)
+		}
+		want, _ := mdl.Predict(exa)
+		exa.Free()
+
+		if got != want {
+			t.Fatalf("PredictMap returned %f, expected %f", got, want)
+		}
+	}
+
+	if _, err := mdl.PredictMap(map[int]float64{0: 1}); err == nil {
+		t.Error("Expected an error for a non-positive feature index")
+	}
+}
+
+func TestPredictBatch(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	_, examples := readDenseData(t, "testdata/a1a", 123)
+	nodes := make([]*SvmNode, 100)
+	for i := range nodes {
+		nodes[i] = NewExample(1, examples[i])
+	}
+
+	res, err := mdl.PredictBatch(nodes)
+	if err != nil {
+		t.Fatal("PredictBatch error was non-nil", err)
+	}
+
+	for i, node := range nodes {
+		v, _ := mdl.Predict(node)
+		if res[i] != v {
+			t.Errorf("Batch prediction %d was %f, expected %f", i, res[i], v)
+		}
+	}
+
+	nodes[3] = nil
+	if _, err := mdl.PredictBatch(nodes); err == nil || !strings.Contains(err.Error(), "index 3") {
+		t.Error("Expected an error naming the nil node index, got", err)
+	}
+}
+
+func TestConcurrentPredict(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	_, examples := readDenseData(t, "testdata/a1a", 123)
+	nodes := make([]*SvmNode, 200)
+	want := make([]float64, len(nodes))
+	for i := range nodes {
+		nodes[i] = NewExample(1, examples[i])
+		want[i], _ = mdl.Predict(nodes[i])
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 50)
+	for g := 0; g < 50; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, node := range nodes {
+				got, err := mdl.Predict(node)
+				if err != nil || got != want[i] {
+					errs <- fmt.Sprintf("example %d predicted %f (%v), expected %f", i, got, err, want[i])
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	for e := range errs {
+		t.Error(e)
+	}
+}
+
+func benchmarkNodes(b *testing.B, n int) (*SvmModel, []*SvmNode) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		b.Fatal("Model load error was non-nil", err)
+	}
+
+	_, examples := readDenseData(b, "testdata/a1a.t", 123)
+	nodes := make([]*SvmNode, n)
+	for i := range nodes {
+		nodes[i] = NewExample(1, examples[i%len(examples)])
+	}
+
+	return mdl, nodes
+}
+
+func BenchmarkPredict(b *testing.B) {
+	mdl, nodes := benchmarkNodes(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, node := range nodes {
+			mdl.Predict(node)
+		}
+	}
+}
+
+func BenchmarkPredictBatch(b *testing.B) {
+	mdl, nodes := benchmarkNodes(b, 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mdl.PredictBatch(nodes)
+	}
+}
+
+func TestPredictInto(t *testing.T) {
+	param := NewParameter(C_SVC, RBF)
+	param.SetGammaAuto(2)
+	param.EnableProbability(true)
+	mdl, prob := trainThreeClass(t, param)
+	defer FreeProblem(prob)
+	defer FreeModel(mdl)
+
+	exa := NewExample(1, []float64{2, 1})
+	defer exa.Free()
+
+	buf := make([]float64, 3)
+	label, err := mdl.PredictValuesInto(exa, buf)
+	if err != nil {
+		t.Fatal("PredictValuesInto error was non-nil", err)
+	}
+
+	wantLabel, want, _ := mdl.PredictValues(exa)
+	if label != wantLabel || fmt.Sprint(buf) != fmt.Sprint(want) {
+		t.Errorf("PredictValuesInto gave %f %v, PredictValues gave %f %v", label, buf, wantLabel, want)
+	}
+
+	label, err = mdl.PredictProbabilityInto(exa, buf)
+	if err != nil {
+		t.Fatal("PredictProbabilityInto error was non-nil", err)
+	}
+
+	wantLabel, want, _ = mdl.PredictProbability(exa)
+	if label != wantLabel || fmt.Sprint(buf) != fmt.Sprint(want) {
+		t.Errorf("PredictProbabilityInto gave %f %v, PredictProbability gave %f %v", label, buf, wantLabel, want)
+	}
+
+	if _, err := mdl.PredictValuesInto(exa, buf[:2]); err == nil {
+		t.Error("Expected an error for a buffer that is too small")
+	}
+
+	if _, err := mdl.PredictProbabilityInto(exa, nil); err == nil {
+		t.Error("Expected an error for a nil buffer")
+	}
+}
+
+func BenchmarkPredictValues(b *testing.B) {
+	mdl, nodes := benchmarkNodes(b, 1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mdl.PredictValues(nodes[i%len(nodes)])
+	}
+}
+
+func BenchmarkPredictValuesInto(b *testing.B) {
+	mdl, nodes := benchmarkNodes(b, 1000)
+	buf := make([]float64, 1)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mdl.PredictValuesInto(nodes[i%len(nodes)], buf)
+	}
+}
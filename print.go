@@ -0,0 +1,140 @@
+package libsvm
+
+/*
+void libsvm_capture_print(void);
+*/
+import "C"
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	printDefault = 0
+	printQuiet   = 1
+	printGo      = 2
+)
+
+var (
+	printMu   sync.RWMutex
+	printFunc func(string)
+	printMode = printDefault
+)
+
+// The last complete line LIBSVM printed, and how many lines it has printed,
+// so a failed training can report why
+var (
+	lastMu   sync.Mutex
+	lastLine string
+	lastSeq  uint64
+	partial  strings.Builder
+)
+
+func init() {
+	C.libsvm_capture_print()
+}
+
+// SetQuiet silences the training progress and warnings LIBSVM writes to
+// stdout. Passing false restores the default output.
+// The setting is process wide and replaces any function set by SetPrintFunc.
+func SetQuiet(quiet bool) {
+	if quiet {
+		setPrint(printQuiet, nil)
+	} else {
+		setPrint(printDefault, nil)
+	}
+}
+
+// SetPrintFunc routes everything LIBSVM would print to stdout through fn,
+// for example into an application logger. Passing nil restores the default
+// output. The setting is process wide, and fn may be called from any goroutine
+// that trains or cross validates.
+func SetPrintFunc(fn func(string)) {
+	if fn == nil {
+		setPrint(printDefault, nil)
+	} else {
+		setPrint(printGo, fn)
+	}
+}
+
+// setPrint switches LIBSVM output to the given mode and Go print function
+func setPrint(mode int, fn func(string)) {
+	printMu.Lock()
+	defer printMu.Unlock()
+
+	printMode, printFunc = mode, fn
+}
+
+// printState returns the current output mode and Go print function
+func printState() (int, func(string)) {
+	printMu.RLock()
+	defer printMu.RUnlock()
+
+	return printMode, printFunc
+}
+
+//export goPrintString
+func goPrintString(s *C.char) {
+	msg := C.GoString(s)
+	recordPrint(msg)
+	emit(msg)
+}
+
+// recordPrint remembers the last complete line of LIBSVM output, ignoring the
+// dots and stars it prints as solver progress
+func recordPrint(s string) {
+	lastMu.Lock()
+	defer lastMu.Unlock()
+
+	for _, r := range s {
+		if r != '\n' {
+			partial.WriteRune(r)
+			continue
+		}
+
+		if line := strings.TrimLeft(partial.String(), ".*"); line != "" {
+			lastLine = line
+			lastSeq++
+		}
+		partial.Reset()
+	}
+}
+
+// printSeq returns the number of lines LIBSVM has printed so far
+func printSeq() uint64 {
+	lastMu.Lock()
+	defer lastMu.Unlock()
+
+	return lastSeq
+}
+
+// lastPrint returns the last line LIBSVM printed, if it printed any after
+// the sequence number since. Output is process wide, so with concurrent
+// trainings the line may come from another run.
+func lastPrint(since uint64) (string, bool) {
+	lastMu.Lock()
+	defer lastMu.Unlock()
+
+	return lastLine, lastSeq > since
+}
+
+// emit writes s to wherever output is currently going
+func emit(s string) {
+	mode, fn := printState()
+	switch mode {
+	case printQuiet:
+	case printGo:
+		fn(s)
+	default:
+		os.Stdout.WriteString(s)
+	}
+}
+
+// printf writes a message from the wrapper itself to wherever LIBSVM output
+// is currently going, so warnings honour SetQuiet and SetPrintFunc
+func printf(format string, args ...interface{}) {
+	emit(fmt.Sprintf(format, args...))
+}
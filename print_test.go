@@ -0,0 +1,83 @@
+package libsvm
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSetPrintFunc(t *testing.T) {
+	var mu sync.Mutex
+	var out strings.Builder
+	SetPrintFunc(func(s string) {
+		mu.Lock()
+		out.WriteString(s)
+		mu.Unlock()
+	})
+	defer SetPrintFunc(nil)
+
+	prob, err := NewProblem([]float64{1, 1, -1, -1}, [][]float64{{1, 1}, {1, 0.8}, {-1, -1}, {-0.8, -1}})
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	param := NewParameter(C_SVC, LINEAR)
+	defer FreeParam(param)
+
+	mdl, err := Train(*prob, *param)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.Contains(out.String(), "optimization finished") {
+		t.Error("Expected training output to be routed to the print func, got", out.String())
+	}
+}
+
+func TestSetQuiet(t *testing.T) {
+	SetPrintFunc(func(s string) {
+		t.Error("Print func should have been replaced by SetQuiet")
+	})
+	SetQuiet(true)
+	defer SetQuiet(false)
+
+	prob, err := NewProblem([]float64{1, -1}, [][]float64{{1}, {-1}})
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	param := NewParameter(C_SVC, LINEAR)
+	defer FreeParam(param)
+
+	if _, err := Train(*prob, *param); err != nil {
+		t.Error("Train error was non-nil", err)
+	}
+}
+
+func TestTrainFailureMessage(t *testing.T) {
+	// svm_train has no reliable way to fail on demand, so feed the output it
+	// would print straight to the recorder
+	since := printSeq()
+	if err := trainFailure(since); !strings.Contains(err.Error(), "nil model returned") {
+		t.Error("Expected the generic message when nothing was printed, got", err)
+	}
+
+	recordPrint("......")
+	recordPrint("\nWARNING: reaching max number of iterations\n")
+	recordPrint("....")
+
+	err := trainFailure(since)
+	if !errors.Is(err, ErrTrainFailed) || err.Error() != "error while training: WARNING: reaching max number of iterations" {
+		t.Error("Expected the last printed line in the training error, got", err)
+	}
+
+	if _, ok := lastPrint(printSeq()); ok {
+		t.Error("Expected no new output since the latest sequence number")
+	}
+}
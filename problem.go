@@ -0,0 +1,289 @@
+package libsvm
+
+/*
+#include <svm.h>
+#include <stdlib.h>
+
+extern const struct svm_node TERMINATOR;
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"unsafe"
+)
+
+// NewProblem builds a problem from dense Go slices. Each example is stored with
+// feature indices starting at 1, and labels[i] is the label of examples[i].
+// The problem must be released with FreeProblem once it, and every model
+// trained from it, is no longer in use.
+func NewProblem(labels []float64, examples [][]float64) (*SvmProblem, error) {
+	if len(labels) != len(examples) {
+		return nil, SvmError{Message: fmt.Sprintf("label count %d does not match example count %d", len(labels), len(examples))}
+	}
+
+	if len(examples) == 0 {
+		return nil, SvmError{Message: "no examples when attempting to build an svm problem"}
+	}
+
+	total := 0
+	for i, ex := range examples {
+		if len(ex) == 0 {
+			return nil, SvmError{Message: fmt.Sprintf("example %d is empty", i)}
+		}
+		total += len(ex) + 1
+	}
+
+	prob := allocProblem(len(examples), total)
+	ys := unsafe.Slice(prob.object.y, len(examples))
+	xs := unsafe.Slice(prob.object.x, len(examples))
+	space := unsafe.Slice(prob.space, total)
+
+	k := 0
+	for i, ex := range examples {
+		ys[i] = C.double(labels[i])
+		xs[i] = &space[k]
+		for j, v := range ex {
+			space[k].index = C.int(j + 1)
+			space[k].value = C.double(v)
+			k++
+		}
+		space[k] = C.TERMINATOR
+		k++
+	}
+
+	return prob, nil
+}
+
+// NewPrecomputedProblem builds a problem for training with the PRECOMPUTED
+// kernel from a square Gram matrix, where gram[i][j] is K(x_i, x_j). Each row
+// is stored with its serial number i+1 at index 0, as LIBSVM requires.
+func NewPrecomputedProblem(labels []float64, gram [][]float64) (*SvmProblem, error) {
+	if len(labels) != len(gram) {
+		return nil, SvmError{Message: fmt.Sprintf("label count %d does not match kernel row count %d", len(labels), len(gram))}
+	}
+
+	if len(gram) == 0 {
+		return nil, SvmError{Message: "no examples when attempting to build an svm problem"}
+	}
+
+	indices := make([][]int, len(gram))
+	values := make([][]float64, len(gram))
+	for i, row := range gram {
+		if len(row) != len(gram) {
+			return nil, SvmError{Message: fmt.Sprintf("kernel row %d has %d values, expected %d", i, len(row), len(gram))}
+		}
+
+		indices[i] = make([]int, len(row)+1)
+		values[i] = make([]float64, len(row)+1)
+		values[i][0] = float64(i + 1)
+		for j, v := range row {
+			indices[i][j+1] = j + 1
+			values[i][j+1] = v
+		}
+	}
+
+	return newSparseProblem(labels, indices, values), nil
+}
+
+// newSparseProblem builds a problem from sparse rows, where row i holds the
+// features indices[i] with values values[i]. Rows are assumed to be validated.
+func newSparseProblem(labels []float64, indices [][]int, values [][]float64) *SvmProblem {
+	total := 0
+	for _, idx := range indices {
+		total += len(idx) + 1
+	}
+
+	prob := allocProblem(len(labels), total)
+	ys := unsafe.Slice(prob.object.y, len(labels))
+	xs := unsafe.Slice(prob.object.x, len(labels))
+	space := unsafe.Slice(prob.space, total)
+
+	k := 0
+	for i := range labels {
+		ys[i] = C.double(labels[i])
+		xs[i] = &space[k]
+		for j, idx := range indices[i] {
+			space[k].index = C.int(idx)
+			space[k].value = C.double(values[i][j])
+			k++
+		}
+		space[k] = C.TERMINATOR
+		k++
+	}
+
+	return prob
+}
+
+// allocProblem allocates an svm_problem with room for l rows backed by a
+// single block of nodes entries. The caller is responsible for filling it in.
+func allocProblem(l int, nodes int) *SvmProblem {
+	obj := (*C.struct_svm_problem)(C.calloc(1, C.sizeof_struct_svm_problem))
+	obj.l = C.int(l)
+	obj.y = (*C.double)(C.calloc(C.size_t(l), C.sizeof_double))
+	obj.x = (**C.struct_svm_node)(C.calloc(C.size_t(l), C.size_t(unsafe.Sizeof((*C.struct_svm_node)(nil)))))
+
+	return &SvmProblem{
+		object: obj,
+		space:  (*C.struct_svm_node)(C.calloc(C.size_t(nodes), C.sizeof_struct_svm_node)),
+	}
+}
+
+// FreeProblem will free the underlying svm_problem structure and all of its nodes.
+// It is equivalent to prob.Close().
+func FreeProblem(prob *SvmProblem) error {
+	return prob.Close()
+}
+
+// Close will free the underlying svm_problem structure and all of its nodes
+func (prob *SvmProblem) Close() error {
+
+	if prob == nil {
+		return SvmError{Message: "nil problem when attempting to free an svm problem"}
+	}
+
+	if atomic.LoadInt32(&prob.freed) != 0 {
+		return SvmError{Kind: ErrFreed, Message: "svm problem has already been freed"}
+	}
+
+	if prob.object == nil {
+		return SvmError{Message: "problem object's internal svm_problem pointer is nil when attempting to free an svm problem"}
+	}
+
+	if !atomic.CompareAndSwapInt32(&prob.freed, 0, 1) {
+		return SvmError{Kind: ErrFreed, Message: "svm problem has already been freed"}
+	}
+
+	C.free(unsafe.Pointer(prob.space))
+	C.free(unsafe.Pointer(prob.object.x))
+	C.free(unsafe.Pointer(prob.object.y))
+	C.free(unsafe.Pointer(prob.object))
+	prob.space = nil
+	prob.object = nil
+	return nil
+}
+
+// Append returns a new problem holding the examples of prob followed by
+// those of other, copied into fresh C memory so both originals remain valid
+// and must still be freed. Problems built for the PRECOMPUTED kernel cannot
+// be appended, since each row holds kernel values against its own problem
+// only; build the combined Gram matrix with NewPrecomputedProblem instead.
+func (prob *SvmProblem) Append(other *SvmProblem) (*SvmProblem, error) {
+	if prob == nil || other == nil || prob.object == nil || other.object == nil {
+		return nil, SvmError{Message: "nil problem when attempting to append svm problems"}
+	}
+
+	var labels []float64
+	var rows [][]Pair
+	total := 0
+	for _, p := range []*SvmProblem{prob, other} {
+		l := int(p.object.l)
+		ys := unsafe.Slice(p.object.y, l)
+		for i, x := range unsafe.Slice(p.object.x, l) {
+			row := nodePairs(x)
+			if len(row) > 0 && row[0].Index == 0 {
+				return nil, SvmError{Message: "precomputed kernel problems cannot be appended, their kernel values only cover their own examples"}
+			}
+
+			labels = append(labels, float64(ys[i]))
+			rows = append(rows, row)
+			total += len(row) + 1
+		}
+		runtime.KeepAlive(p)
+	}
+
+	res := allocProblem(len(rows), total)
+	ys := unsafe.Slice(res.object.y, len(rows))
+	xs := unsafe.Slice(res.object.x, len(rows))
+	space := unsafe.Slice(res.space, total)
+
+	k := 0
+	for i, row := range rows {
+		ys[i] = C.double(labels[i])
+		xs[i] = &space[k]
+		for _, p := range row {
+			space[k].index = C.int(p.Index)
+			space[k].value = C.double(p.Value)
+			k++
+		}
+		space[k] = C.TERMINATOR
+		k++
+	}
+
+	return res, nil
+}
+
+// FeatureStats scans the problem and returns its largest feature index and
+// its density, the fraction of the l*maxIndex feature matrix that is
+// non-zero. A maximum index far beyond the expected number of features, or a
+// density far from what the data should have, usually points to a data
+// preparation bug such as misaligned columns or hashed ids used as indices.
+func (prob *SvmProblem) FeatureStats() (int, float64, error) {
+	if prob == nil || prob.object == nil {
+		return 0, 0, SvmError{Message: "nil problem when attempting to compute feature statistics"}
+	}
+	defer runtime.KeepAlive(prob)
+
+	l := int(prob.object.l)
+	max, nonZero := 0, 0
+	for _, row := range unsafe.Slice(prob.object.x, l) {
+		for _, p := range nodePairs(row) {
+			if p.Index > max {
+				max = p.Index
+			}
+			if p.Value != 0 {
+				nonZero++
+			}
+		}
+	}
+
+	if max == 0 {
+		return 0, 0, nil
+	}
+
+	return max, float64(nonZero) / (float64(l) * float64(max)), nil
+}
+
+// Example returns the label and features of the i-th example of the problem,
+// counting from 0. SvIndices counts from 1, so SvIndices()[k]-1 is the
+// example that became support vector k.
+func (prob *SvmProblem) Example(i int) (float64, []Pair, error) {
+	if prob == nil || prob.object == nil {
+		return 0, nil, SvmError{Message: "nil problem when attempting to get an example"}
+	}
+	defer runtime.KeepAlive(prob)
+
+	l := int(prob.object.l)
+	if i < 0 || i >= l {
+		return 0, nil, SvmError{Message: fmt.Sprintf("example %d out of range for a problem of %d examples", i, l)}
+	}
+
+	return float64(unsafe.Slice(prob.object.y, l)[i]), nodePairs(unsafe.Slice(prob.object.x, l)[i]), nil
+}
+
+// problemLabels returns a copy of the labels of the problem
+func problemLabels(prob SvmProblem) []float64 {
+	ys := unsafe.Slice(prob.object.y, int(prob.object.l))
+	labels := make([]float64, len(ys))
+	for i, y := range ys {
+		labels[i] = float64(y)
+	}
+
+	return labels
+}
+
+// maxIndex returns the largest feature index in l terminated node arrays
+func maxIndex(rows **C.struct_svm_node, l int) int {
+	max := 0
+	for _, row := range unsafe.Slice(rows, l) {
+		for _, p := range nodePairs(row) {
+			if p.Index > max {
+				max = p.Index
+			}
+		}
+	}
+
+	return max
+}
@@ -0,0 +1,179 @@
+package libsvm
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestNewProblem(t *testing.T) {
+	prob, err := NewProblem([]float64{1, -1}, [][]float64{{0.5, 1}, {-0.5, 2, 3}})
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+
+	if prob.object.l != 2 {
+		t.Error("Problem length was not set", prob.object.l)
+	}
+
+	row := unsafe.Slice(unsafe.Slice(prob.object.x, 2)[1], 4)
+	if row[0].index != 1 || row[2].index != 3 || row[2].value != 3 || row[3].index != -1 {
+		t.Error("Problem row was not built correctly")
+	}
+
+	if err := FreeProblem(prob); err != nil {
+		t.Error("FreeProblem error was non-nil", err)
+	}
+
+	if _, err := NewProblem([]float64{1}, [][]float64{{1}, {2}}); err == nil {
+		t.Error("Expected an error for mismatched label and example counts")
+	}
+
+	if _, err := NewProblem([]float64{1, 2}, [][]float64{{1}, {}}); err == nil {
+		t.Error("Expected an error for an empty example")
+	}
+}
+
+func TestProblemAppend(t *testing.T) {
+	first, err := NewProblem([]float64{1, -1}, [][]float64{{0.5, 1}, {-0.5, 2}})
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(first)
+
+	second, err := NewProblem([]float64{2, 3, 4}, [][]float64{{1}, {2, 0, 7}, {3}})
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(second)
+
+	both, err := first.Append(second)
+	if err != nil {
+		t.Fatal("Append error was non-nil", err)
+	}
+
+	if both.object.l != 5 {
+		t.Error("Expected the combined problem to hold 5 examples, got", both.object.l)
+	}
+
+	row := nodePairs(unsafe.Slice(both.object.x, 5)[3])
+	if got := unsafe.Slice(both.object.y, 5)[3]; got != 3 || len(row) != 3 || row[2] != (Pair{Index: 3, Value: 7}) {
+		t.Error("Appended row was not copied correctly", got, row)
+	}
+
+	if err := FreeProblem(both); err != nil {
+		t.Error("FreeProblem error was non-nil", err)
+	}
+
+	if first.object.l != 2 || nodePairs(unsafe.Slice(first.object.x, 2)[1])[1].Value != 2 {
+		t.Error("Append modified the original problem")
+	}
+
+	gram, err := NewPrecomputedProblem([]float64{1, -1}, [][]float64{{1, 0}, {0, 1}})
+	if err != nil {
+		t.Fatal("NewPrecomputedProblem error was non-nil", err)
+	}
+	defer FreeProblem(gram)
+
+	if _, err := first.Append(gram); err == nil {
+		t.Error("Expected an error appending a precomputed kernel problem")
+	}
+}
+
+func TestFeatureStats(t *testing.T) {
+	prob := newSparseProblem([]float64{1, -1, 1, -1}, [][]int{{1, 10}, {2}, {5, 7, 8}, {10}}, [][]float64{{1, 1}, {0.5}, {1, 0, 2}, {3}})
+	defer FreeProblem(prob)
+
+	max, density, err := prob.FeatureStats()
+	if err != nil {
+		t.Fatal("FeatureStats error was non-nil", err)
+	}
+
+	// 6 non-zero values in a 4x10 matrix
+	if max != 10 || math.Abs(density-0.15) > 1e-12 {
+		t.Errorf("Expected a max index of 10 and density of 0.15, got %d and %f", max, density)
+	}
+
+	if _, _, err := (*SvmProblem)(nil).FeatureStats(); err == nil {
+		t.Error("Expected an error for a nil problem")
+	}
+}
+
+func TestPrecomputedKernel(t *testing.T) {
+	train := []float64{-2, -1, 1, 2}
+	labels := []float64{-1, -1, 1, 1}
+	kernelRow := func(x float64) []float64 {
+		row := make([]float64, len(train))
+		for j, xj := range train {
+			row[j] = x * xj
+		}
+		return row
+	}
+
+	gram := make([][]float64, len(train))
+	for i, x := range train {
+		gram[i] = kernelRow(x)
+	}
+
+	prob, err := NewPrecomputedProblem(labels, gram)
+	if err != nil {
+		t.Fatal("NewPrecomputedProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	param := NewParameter(C_SVC, PRECOMPUTED)
+	defer FreeParam(param)
+
+	mdl, err := Train(*prob, *param)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	for _, c := range []struct{ x, want float64 }{{-1.5, -1}, {1.5, 1}} {
+		exa, err := NewPrecomputedExample(1, kernelRow(c.x))
+		if err != nil {
+			t.Fatal("NewPrecomputedExample error was non-nil", err)
+		}
+
+		if v, _ := mdl.Predict(exa); v != c.want {
+			t.Errorf("Predicted %f for %f, expected %f", v, c.x, c.want)
+		}
+		exa.Free()
+	}
+
+	if _, err := NewPrecomputedExample(0, []float64{1}); err == nil {
+		t.Error("Expected an error for a serial number below 1")
+	}
+
+	fn := filepath.Join(t.TempDir(), "precomputed.model")
+	if err := mdl.Save(fn); err != nil {
+		t.Fatal("Save error was non-nil", err)
+	}
+
+	loaded, err := Load(fn)
+	if err != nil {
+		t.Fatal("Load error was non-nil", err)
+	}
+	defer FreeModel(loaded)
+
+	plain := NewExample(1, kernelRow(1.5))
+	defer plain.Free()
+
+	if _, err := loaded.Predict(plain); err == nil || !strings.Contains(err.Error(), "NewPrecomputedExample") {
+		t.Error("Expected an error predicting an ordinary example with a PRECOMPUTED model, got", err)
+	}
+
+	if _, err := loaded.PredictBatch([]*SvmNode{plain}); !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "index 0") {
+		t.Error("Expected an ErrInvalidInput error predicting an ordinary example in a batch, got", err)
+	}
+
+	exa, _ := NewPrecomputedExample(1, kernelRow(1.5))
+	defer exa.Free()
+	if v, err := loaded.Predict(exa); err != nil || v != 1 {
+		t.Error("Expected the loaded model to predict a precomputed example, got", v, err)
+	}
+}
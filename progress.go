@@ -0,0 +1,185 @@
+package libsvm
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Progress is a training progress report parsed from LIBSVM's output.
+// Multi-class problems are trained as one binary sub-problem per pair of
+// classes, and each sub-problem reports separately.
+type Progress struct {
+	// Iterations is the solver iteration count of the current sub-problem.
+	// While the solver runs it is counted from the marks LIBSVM prints every
+	// min(n, 1000) iterations of a sub-problem with n variables: the examples
+	// of the pair of classes, or twice the examples for regression. Once the
+	// sub-problem is finished it is the exact count LIBSVM reports.
+	Iterations int
+	// Finished is set once the sub-problem is solved, when the remaining
+	// fields are filled in from LIBSVM's summary
+	Finished bool
+	// Nu is the fraction of bounded support vectors reported for C_SVC and
+	// EPSILON_SVR; it is 0 for other svm types
+	Nu                    float64
+	Objective             float64
+	Rho                   float64
+	SupportVectors        int
+	BoundedSupportVectors int
+	// IterationLimit is set when the solver gave up at LIBSVM's iteration
+	// limit before reaching the eps tolerance
+	IterationLimit bool
+}
+
+// progressMu serialises trainings that listen to LIBSVM's output
+var progressMu sync.Mutex
+
+// TrainWithProgress is like Train, but calls fn with a Progress report each
+// time LIBSVM marks solver progress, every min(n, 1000) iterations as
+// described for Progress, and whenever a sub-problem finishes. Reports are
+// parsed from LIBSVM's output, so fn is only called while the output is
+// captured: it is still passed on to any function set by SetPrintFunc, or to
+// stdout, unless SetQuiet is in effect. LIBSVM's output is process wide, so
+// other trainings running at the same time interleave their reports, and
+// changes to the print settings made during training are lost.
+func TrainWithProgress(prob SvmProblem, param SvmParameter, fn func(Progress)) (*SvmModel, error) {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+
+	mode, prev := printState()
+	defer setPrint(mode, prev)
+
+	parser := &progressParser{fn: fn}
+	if prob.object != nil && param.object != nil {
+		parser.steps, parser.fallback = progressSteps(prob, param)
+	}
+	setPrint(printGo, func(s string) {
+		parser.write(s)
+		switch mode {
+		case printGo:
+			prev(s)
+		case printDefault:
+			fmt.Print(s)
+		}
+	})
+
+	return Train(prob, param)
+}
+
+// progressParser turns LIBSVM's output into Progress reports
+type progressParser struct {
+	fn      func(Progress)
+	line    strings.Builder
+	current Progress
+	// steps holds the iterations between progress marks of each sub-problem
+	// in training order, and fallback those of any sub-problem beyond them
+	steps    []int
+	fallback int
+	sub      int
+}
+
+// progressSteps returns the iterations between LIBSVM's progress marks for
+// each sub-problem the training will solve, in order, and the best guess for
+// sub-problems not in the list. Probability estimates add cross validation
+// trainings on subsets of the data, whose sizes cannot be predicted, so only
+// the guess is returned for them.
+func progressSteps(prob SvmProblem, param SvmParameter) ([]int, int) {
+	l := int(prob.object.l)
+	probability := param.object.probability != 0
+
+	switch SvmType(param.object.svm_type) {
+	case C_SVC, NU_SVC:
+		if probability {
+			return nil, markInterval(l)
+		}
+
+		// LIBSVM groups classes by first appearance and solves every pair
+		counts := make(map[float64]int)
+		var order []float64
+		for _, y := range problemLabels(prob) {
+			if counts[y] == 0 {
+				order = append(order, y)
+			}
+			counts[y]++
+		}
+
+		var steps []int
+		for i := range order {
+			for j := i + 1; j < len(order); j++ {
+				steps = append(steps, markInterval(counts[order[i]]+counts[order[j]]))
+			}
+		}
+		return steps, markInterval(l)
+	case EPSILON_SVR, NU_SVR:
+		if probability {
+			return nil, markInterval(2 * l)
+		}
+		return []int{markInterval(2 * l)}, markInterval(2 * l)
+	}
+
+	return []int{markInterval(l)}, markInterval(l)
+}
+
+// markInterval returns how many iterations LIBSVM's solver runs between
+// progress marks for a sub-problem with n variables
+func markInterval(n int) int {
+	if n > 1000 {
+		return 1000
+	}
+
+	return n
+}
+
+// write consumes a piece of LIBSVM output, which need not be a whole line
+func (p *progressParser) write(s string) {
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			p.parseLine(p.line.String())
+			p.line.Reset()
+		case r == '.' && p.line.Len() == 0:
+			step := p.fallback
+			if p.sub < len(p.steps) {
+				step = p.steps[p.sub]
+			}
+			p.current.Iterations += step
+			p.fn(p.current)
+		case r == '*' && p.line.Len() == 0:
+			// shrinking was undone, which is not reported
+		default:
+			p.line.WriteRune(r)
+		}
+	}
+}
+
+// parseLine picks the solver summary out of a line of output, reporting the
+// sub-problem once its support vector counts are known
+func (p *progressParser) parseLine(line string) {
+	var a, b float64
+	var n, m int
+
+	switch {
+	case strings.HasPrefix(line, "WARNING: reaching max number of iterations"):
+		p.current.IterationLimit = true
+	case strings.HasPrefix(line, "optimization finished"):
+		if _, err := fmt.Sscanf(line, "optimization finished, #iter = %d", &n); err == nil {
+			p.current.Iterations = n
+		}
+	case strings.HasPrefix(line, "nu = "):
+		if _, err := fmt.Sscanf(line, "nu = %g", &a); err == nil {
+			p.current.Nu = a
+		}
+	case strings.HasPrefix(line, "obj = "):
+		if _, err := fmt.Sscanf(line, "obj = %g, rho = %g", &a, &b); err == nil {
+			p.current.Objective, p.current.Rho = a, b
+		}
+	case strings.HasPrefix(line, "nSV = "):
+		if _, err := fmt.Sscanf(line, "nSV = %d, nBSV = %d", &n, &m); err == nil {
+			p.current.SupportVectors, p.current.BoundedSupportVectors = n, m
+		}
+		p.current.Finished = true
+		p.fn(p.current)
+		p.current = Progress{}
+		p.sub++
+	}
+}
@@ -0,0 +1,81 @@
+package libsvm
+
+import "testing"
+
+func TestTrainWithProgress(t *testing.T) {
+	prob, param, _ := a1aProblem(t)
+	defer FreeProblem(prob)
+	defer FreeParam(param)
+
+	SetQuiet(true)
+	defer SetQuiet(false)
+
+	var reports []Progress
+	mdl, err := TrainWithProgress(*prob, *param, func(p Progress) {
+		reports = append(reports, p)
+	})
+	if err != nil {
+		t.Fatal("TrainWithProgress error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	if len(reports) == 0 {
+		t.Fatal("Expected at least one progress report")
+	}
+
+	last := reports[len(reports)-1]
+	if !last.Finished || last.Iterations == 0 || last.SupportVectors != mdl.TotalSv() {
+		t.Errorf("Unexpected final progress report %+v for a model with %d support vectors", last, mdl.TotalSv())
+	}
+
+	if mode, fn := printState(); mode != printQuiet || fn != nil {
+		t.Error("Expected the quiet setting to be restored after training")
+	}
+}
+
+func TestProgressParser(t *testing.T) {
+	var reports []Progress
+	p := &progressParser{fn: func(pr Progress) { reports = append(reports, pr) }, fallback: 1000}
+	for _, s := range []string{".", ".", "*", "\noptimization finished, #iter = 2345\n", "nu = 0.25\n", "obj = -12.5, rho = 0.75\n", "nSV = 40, nBSV = 10\n", "Total nSV = 40\n"} {
+		p.write(s)
+	}
+
+	want := Progress{Iterations: 2345, Finished: true, Nu: 0.25, Objective: -12.5, Rho: 0.75, SupportVectors: 40, BoundedSupportVectors: 10}
+	if len(reports) != 3 || reports[1].Iterations != 2000 || reports[2] != want {
+		t.Errorf("Unexpected progress reports %+v", reports)
+	}
+}
+
+func TestProgressSteps(t *testing.T) {
+	labels, X := threeClassData()
+	labels = append(labels, 3, 3)
+	X = append(X, []float64{-5, -5}, []float64{-5, -4.9})
+
+	prob, err := NewProblem(labels, X)
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	param := NewParameter(C_SVC, LINEAR)
+	defer FreeParam(param)
+
+	// classes 1, 2 and 3 hold 10, 10 and 12 examples
+	steps, fallback := progressSteps(*prob, *param)
+	if len(steps) != 3 || steps[0] != 20 || steps[1] != 22 || steps[2] != 22 || fallback != 32 {
+		t.Errorf("Unexpected progress steps %v, fallback %d", steps, fallback)
+	}
+
+	var reports []Progress
+	p := &progressParser{fn: func(pr Progress) { reports = append(reports, pr) }, steps: steps, fallback: fallback}
+	p.write("..\noptimization finished, #iter = 45\nnSV = 3, nBSV = 0\n.")
+	if len(reports) != 4 || reports[1].Iterations != 40 || reports[2].Iterations != 45 || reports[3].Iterations != 22 {
+		t.Errorf("Unexpected progress reports %+v", reports)
+	}
+
+	svr := NewParameter(EPSILON_SVR, LINEAR)
+	defer FreeParam(svr)
+	if steps, _ := progressSteps(*prob, *svr); len(steps) != 1 || steps[0] != 64 {
+		t.Error("Expected a single regression step of twice the examples, got", steps)
+	}
+}
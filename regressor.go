@@ -0,0 +1,115 @@
+package libsvm
+
+import "fmt"
+
+// Regressor is a convenience wrapper that trains an EPSILON_SVR, or NU_SVR,
+// model from dense Go slices and owns the underlying C resources, which are
+// released by Close.
+type Regressor struct {
+	cfg config
+	est estimator
+}
+
+// NewRegressor creates a regressor configured by opts
+func NewRegressor(opts ...Option) *Regressor {
+	return &Regressor{cfg: newConfig(EPSILON_SVR, opts)}
+}
+
+// Fit trains the regressor, where y[i] is the target of X[i].
+// Calling Fit again replaces the previously trained model.
+func (reg *Regressor) Fit(y []float64, X [][]float64) error {
+	return reg.est.fit(reg.cfg, y, X)
+}
+
+// Predict returns the predicted value of x
+func (reg *Regressor) Predict(x []float64) (float64, error) {
+	return reg.est.predict(x)
+}
+
+// Close releases the C resources owned by the regressor
+func (reg *Regressor) Close() error {
+	reg.est.close()
+	return nil
+}
+
+// MultiRegressor is a convenience wrapper for multi-target regression that
+// trains one EPSILON_SVR model per target and owns their C resources, which
+// are released by Close.
+type MultiRegressor struct {
+	cfg  config
+	ests []estimator
+}
+
+// NewMultiRegressor creates a multi-target regressor configured by opts, which
+// apply to every per-target model
+func NewMultiRegressor(opts ...Option) *MultiRegressor {
+	return &MultiRegressor{cfg: newConfig(EPSILON_SVR, opts)}
+}
+
+// Fit trains one model per target, where Y[i] holds the targets of X[i] and
+// every row of Y has the same number of targets.
+// Calling Fit again replaces the previously trained models.
+func (reg *MultiRegressor) Fit(Y [][]float64, X [][]float64) error {
+	reg.Close()
+
+	if len(Y) != len(X) {
+		return SvmError{Message: fmt.Sprintf("target row count %d does not match example count %d", len(Y), len(X))}
+	}
+
+	if len(Y) == 0 || len(Y[0]) == 0 {
+		return SvmError{Message: "no targets when attempting to fit a multi-target regressor"}
+	}
+
+	targets := len(Y[0])
+	for i, y := range Y {
+		if len(y) != targets {
+			return SvmError{Message: fmt.Sprintf("target row %d has %d targets, expected %d", i, len(y), targets)}
+		}
+	}
+
+	reg.ests = make([]estimator, targets)
+	column := make([]float64, len(Y))
+	for j := range reg.ests {
+		for i, y := range Y {
+			column[i] = y[j]
+		}
+
+		if err := reg.ests[j].fit(reg.cfg, column, X); err != nil {
+			reg.Close()
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Predict returns one predicted value per target for x
+func (reg *MultiRegressor) Predict(x []float64) ([]float64, error) {
+	if len(reg.ests) == 0 {
+		return nil, SvmError{Message: "model has not been fit when attempting to predict"}
+	}
+
+	node := NewExample(1, x)
+	defer node.Free()
+
+	res := make([]float64, len(reg.ests))
+	for j, est := range reg.ests {
+		v, err := est.model.Predict(node)
+		if err != nil {
+			return nil, err
+		}
+		res[j] = v
+	}
+
+	return res, nil
+}
+
+// Close releases the C resources of every per-target model
+func (reg *MultiRegressor) Close() error {
+	for j := range reg.ests {
+		reg.ests[j].close()
+	}
+	reg.ests = nil
+
+	return nil
+}
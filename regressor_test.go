@@ -0,0 +1,78 @@
+package libsvm
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRegressor(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var X [][]float64
+	var y []float64
+	for i := 0; i < 50; i++ {
+		x := float64(i) / 10
+		X = append(X, []float64{x})
+		y = append(y, 3*x-1+0.05*rnd.NormFloat64())
+	}
+
+	for _, opts := range [][]Option{
+		{WithKernel(LINEAR), WithC(100), WithEpsilon(0.05)},
+		{WithKernel(LINEAR), WithC(100), WithNuSVR(0.5)},
+	} {
+		reg := NewRegressor(opts...)
+		if err := reg.Fit(y, X); err != nil {
+			t.Fatal("Fit error was non-nil", err)
+		}
+
+		if SvmType(reg.est.param.object.svm_type) != reg.cfg.svmType {
+			t.Error("Unexpected svm type", SvmType(reg.est.param.object.svm_type))
+		}
+
+		got, err := reg.Predict([]float64{2.55})
+		if err != nil {
+			t.Fatal("Predict error was non-nil", err)
+		}
+
+		if math.Abs(got-6.65) > 0.2 {
+			t.Errorf("%s predicted %f, expected about 6.65", reg.cfg.svmType, got)
+		}
+		reg.Close()
+	}
+}
+
+func TestMultiRegressor(t *testing.T) {
+	var X, Y [][]float64
+	for i := 0; i < 20; i++ {
+		x := float64(i) / 10
+		X = append(X, []float64{x})
+		Y = append(Y, []float64{2 * x, 2*x + 1})
+	}
+
+	reg := NewMultiRegressor(WithKernel(LINEAR), WithC(100))
+	defer reg.Close()
+
+	if err := reg.Fit(Y, X); err != nil {
+		t.Fatal("Fit error was non-nil", err)
+	}
+
+	got, err := reg.Predict([]float64{1.05})
+	if err != nil {
+		t.Fatal("Predict error was non-nil", err)
+	}
+
+	want := []float64{2.1, 3.1}
+	if len(got) != len(want) {
+		t.Fatal("Expected one prediction per target, got", got)
+	}
+
+	for j := range want {
+		if math.Abs(got[j]-want[j]) > 0.2 {
+			t.Errorf("Target %d predicted %f, expected %f", j, got[j], want[j])
+		}
+	}
+
+	if err := reg.Fit([][]float64{{1, 2}, {1}}, [][]float64{{1}, {2}}); err == nil {
+		t.Error("Expected an error for ragged targets")
+	}
+}
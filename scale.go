@@ -0,0 +1,199 @@
+package libsvm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Scaler linearly maps each feature into a fixed range, like LIBSVM's
+// svm-scale. Feature ranges learnt by Fit at training time can be saved with
+// SaveRange and reapplied at prediction time with LoadRange.
+type Scaler struct {
+	lower, upper float64
+	// min and max hold one range per feature seen by Fit or listed by
+	// LoadRange, so their length is the number of features scaled
+	min, max []float64
+}
+
+// NewScaler creates a scaler mapping features into [lower, upper].
+// svm-scale uses [-1, 1] by default. An error is returned unless lower is
+// below upper.
+func NewScaler(lower, upper float64) (*Scaler, error) {
+	if err := checkScaleBounds(lower, upper); err != nil {
+		return nil, err
+	}
+
+	return &Scaler{lower: lower, upper: upper}, nil
+}
+
+// checkScaleBounds ensures a target range is non-empty
+func checkScaleBounds(lower, upper float64) error {
+	if !(lower < upper) {
+		return SvmError{Kind: ErrInvalidParameter, Message: fmt.Sprintf("invalid scaling range [%g, %g]: lower must be below upper", lower, upper)}
+	}
+
+	return nil
+}
+
+// Fit records the minimum and maximum of every feature in X
+func (s *Scaler) Fit(X [][]float64) {
+	width := 0
+	for _, x := range X {
+		if len(x) > width {
+			width = len(x)
+		}
+	}
+
+	s.min = make([]float64, width)
+	s.max = make([]float64, width)
+	seen := make([]bool, width)
+	for _, x := range X {
+		for j, v := range x {
+			if !seen[j] || v < s.min[j] {
+				s.min[j] = v
+			}
+			if !seen[j] || v > s.max[j] {
+				s.max[j] = v
+			}
+			seen[j] = true
+		}
+	}
+}
+
+// Transform returns a scaled copy of X. As with svm-scale, features that were
+// constant during Fit are mapped to 0, and features beyond those seen during
+// Fit are left unchanged.
+func (s *Scaler) Transform(X [][]float64) [][]float64 {
+	res := make([][]float64, len(X))
+	for i, x := range X {
+		res[i] = make([]float64, len(x))
+		for j, v := range x {
+			switch {
+			case j >= len(s.min):
+				res[i][j] = v
+			case s.min[j] == s.max[j]:
+				res[i][j] = 0
+			case v == s.min[j]:
+				res[i][j] = s.lower
+			case v == s.max[j]:
+				res[i][j] = s.upper
+			default:
+				res[i][j] = s.lower + (s.upper-s.lower)*(v-s.min[j])/(s.max[j]-s.min[j])
+			}
+		}
+	}
+
+	return res
+}
+
+// InverseTransform maps scaled values in X back to the original feature
+// ranges. Features that were constant during Fit are restored to that constant.
+func (s *Scaler) InverseTransform(X [][]float64) [][]float64 {
+	res := make([][]float64, len(X))
+	for i, x := range X {
+		res[i] = make([]float64, len(x))
+		for j, v := range x {
+			switch {
+			case j >= len(s.min):
+				res[i][j] = v
+			case s.min[j] == s.max[j]:
+				res[i][j] = s.min[j]
+			default:
+				res[i][j] = s.min[j] + (v-s.lower)*(s.max[j]-s.min[j])/(s.upper-s.lower)
+			}
+		}
+	}
+
+	return res
+}
+
+// SaveRange writes the scaling ranges in the format of svm-scale -s. Every
+// feature is written, constant ones included, so that LoadRange restores
+// the same number of features and Transform scales them identically.
+func (s *Scaler) SaveRange(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "x\n%.16g %.16g\n", s.lower, s.upper)
+	for j := range s.min {
+		fmt.Fprintf(bw, "%d %.16g %.16g\n", j+1, s.min[j], s.max[j])
+	}
+
+	return bw.Flush()
+}
+
+// LoadRange reads scaling ranges written by SaveRange or svm-scale -s,
+// replacing any ranges learnt by Fit. Target (y) scaling is not supported, so
+// a y section in the file is skipped. Features below the largest listed index
+// but missing from the file, as svm-scale leaves out constant features, are
+// treated as constant.
+func (s *Scaler) LoadRange(r io.Reader) error {
+	scanner := newLineScanner(r)
+	lineNo := 0
+	next := func() ([]string, bool) {
+		for scanner.Scan() {
+			lineNo++
+			if fields := strings.Fields(scanner.Text()); len(fields) > 0 {
+				return fields, true
+			}
+		}
+		return nil, false
+	}
+
+	fields, ok := next()
+	if ok && fields[0] == "y" {
+		// skip the y bounds and range lines
+		next()
+		next()
+		fields, ok = next()
+	}
+
+	if !ok || fields[0] != "x" {
+		return SvmError{Message: "invalid range data: missing x section"}
+	}
+
+	fields, ok = next()
+	if !ok || len(fields) != 2 {
+		return SvmError{Message: fmt.Sprintf("line %d: invalid range data: expected lower and upper bounds", lineNo)}
+	}
+
+	lower, lerr := strconv.ParseFloat(fields[0], 64)
+	upper, uerr := strconv.ParseFloat(fields[1], 64)
+	if lerr != nil || uerr != nil {
+		return SvmError{Message: fmt.Sprintf("line %d: invalid range bounds", lineNo)}
+	}
+
+	if err := checkScaleBounds(lower, upper); err != nil {
+		return SvmError{Kind: ErrInvalidParameter, Message: fmt.Sprintf("line %d: %s", lineNo, err)}
+	}
+
+	var min, max []float64
+	for fields, ok = next(); ok; fields, ok = next() {
+		if len(fields) != 3 {
+			return SvmError{Message: fmt.Sprintf("line %d: invalid range data: expected index min max", lineNo)}
+		}
+
+		idx, ierr := strconv.Atoi(fields[0])
+		lo, lerr := strconv.ParseFloat(fields[1], 64)
+		hi, herr := strconv.ParseFloat(fields[2], 64)
+		if ierr != nil || lerr != nil || herr != nil || idx < 1 {
+			return SvmError{Message: fmt.Sprintf("line %d: invalid feature range", lineNo)}
+		}
+
+		for len(min) < idx {
+			min = append(min, 0)
+			max = append(max, 0)
+		}
+		min[idx-1] = lo
+		max[idx-1] = hi
+	}
+
+	if err := scanner.Err(); err != nil {
+		return SvmError{Message: fmt.Sprintf("error reading range data: %s", err)}
+	}
+
+	s.lower, s.upper = lower, upper
+	s.min, s.max = min, max
+	return nil
+}
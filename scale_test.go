@@ -0,0 +1,90 @@
+package libsvm
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestScalerRoundTrip(t *testing.T) {
+	X := [][]float64{{0, 5, 1}, {10, 15, 1}, {5, 10, 1}}
+
+	scaler, err := NewScaler(-1, 1)
+	if err != nil {
+		t.Fatal("NewScaler error was non-nil", err)
+	}
+	scaler.Fit(X)
+
+	scaled := scaler.Transform(X)
+	want := [][]float64{{-1, -1, 0}, {1, 1, 0}, {0, 0, 0}}
+	for i := range want {
+		for j := range want[i] {
+			if math.Abs(scaled[i][j]-want[i][j]) > 1e-12 {
+				t.Errorf("Scaled value [%d][%d] was %f, expected %f", i, j, scaled[i][j], want[i][j])
+			}
+		}
+	}
+
+	restored := scaler.InverseTransform(scaled)
+	for i := range X {
+		for j := range X[i] {
+			if math.Abs(restored[i][j]-X[i][j]) > 1e-12 {
+				t.Errorf("Restored value [%d][%d] was %f, expected %f", i, j, restored[i][j], X[i][j])
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := scaler.SaveRange(&buf); err != nil {
+		t.Fatal("SaveRange error was non-nil", err)
+	}
+
+	if buf.String() != "x\n-1 1\n1 0 10\n2 5 15\n3 1 1\n" {
+		t.Errorf("Unexpected range output %q", buf.String())
+	}
+
+	loaded, _ := NewScaler(0, 1)
+	if err := loaded.LoadRange(&buf); err != nil {
+		t.Fatal("LoadRange error was non-nil", err)
+	}
+
+	// the constant last column must scale as before the reload
+	probe := [][]float64{{5, 10, 7}, {0, 15, 1}}
+	want = scaler.Transform(probe)
+	again := loaded.Transform(probe)
+	for i := range want {
+		for j := range want[i] {
+			if again[i][j] != want[i][j] {
+				t.Errorf("Reloaded value [%d][%d] was %f, expected %f", i, j, again[i][j], want[i][j])
+			}
+		}
+	}
+
+	// svm-scale leaves constant features out of its range files
+	svmScale, _ := NewScaler(-1, 1)
+	if err := svmScale.LoadRange(bytes.NewBufferString("x\n-1 1\n1 0 10\n3 5 15\n")); err != nil {
+		t.Fatal("LoadRange error was non-nil", err)
+	}
+
+	if got := svmScale.Transform([][]float64{{10, 4, 5}}); got[0][0] != 1 || got[0][1] != 0 || got[0][2] != -1 {
+		t.Error("Expected a missing interior feature to be treated as constant, got", got)
+	}
+}
+
+func TestScalerLoadRangeInvalid(t *testing.T) {
+	scaler, _ := NewScaler(-1, 1)
+	if err := scaler.LoadRange(bytes.NewBufferString("1 0 1\n")); err == nil {
+		t.Error("Expected an error for range data without an x section")
+	}
+
+	if err := scaler.LoadRange(bytes.NewBufferString("x\n1 1\n1 0 1\n")); !errors.Is(err, ErrInvalidParameter) {
+		t.Error("Expected an error for an empty target range, got", err)
+	}
+
+	for _, bounds := range [][2]float64{{1, 1}, {1, -1}, {math.NaN(), 1}} {
+		if _, err := NewScaler(bounds[0], bounds[1]); !errors.Is(err, ErrInvalidParameter) {
+			t.Errorf("Expected an error for the range %v, got %v", bounds, err)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package libsvm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+)
+
+// WriteTo writes the model, in the LIBSVM model file format, to w.
+// LIBSVM can only write models to a named file, so the model is staged in a
+// temporary file which is removed afterwards.
+func (mdl *SvmModel) WriteTo(w io.Writer) (int64, error) {
+	f, err := os.CreateTemp("", "libsvm-model-")
+	if err != nil {
+		return 0, SvmError{Kind: ErrSaveFailed, Message: fmt.Sprintf("unable to create temporary model file: %s", err)}
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if err := mdl.Save(f.Name()); err != nil {
+		return 0, err
+	}
+
+	return io.Copy(w, f)
+}
+
+// LoadFrom reads a model in the LIBSVM model file format from r.
+// LIBSVM can only read models from a named file, so the model is staged in a
+// temporary file which is removed afterwards.
+func LoadFrom(r io.Reader) (*SvmModel, error) {
+	f, err := os.CreateTemp("", "libsvm-model-")
+	if err != nil {
+		return nil, SvmError{Kind: ErrLoadFailed, Message: fmt.Sprintf("unable to create temporary model file: %s", err)}
+	}
+	defer os.Remove(f.Name())
+
+	_, err = io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return nil, SvmError{Kind: ErrLoadFailed, Message: fmt.Sprintf("unable to stage model data: %s", err)}
+	}
+
+	return Load(f.Name())
+}
+
+// SaveGz saves the model to filename as a gzip compressed LIBSVM model file.
+// Model files are text and compress well, which helps for models with many
+// support vectors.
+func (mdl *SvmModel) SaveGz(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return SvmError{Kind: ErrSaveFailed, Message: fmt.Sprintf("unable to create model file: %s", err)}
+	}
+	defer f.Close()
+
+	zw := gzip.NewWriter(f)
+	if _, err := mdl.WriteTo(zw); err != nil {
+		return err
+	}
+
+	if err := zw.Close(); err != nil {
+		return SvmError{Kind: ErrSaveFailed, Message: fmt.Sprintf("unable to compress model file: %s", err)}
+	}
+
+	if err := f.Close(); err != nil {
+		return SvmError{Kind: ErrSaveFailed, Message: fmt.Sprintf("unable to write model file: %s", err)}
+	}
+
+	return nil
+}
+
+// LoadGz loads a gzip compressed LIBSVM model file, as written by SaveGz
+func LoadGz(filename string) (*SvmModel, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, SvmError{Kind: ErrLoadFailed, Message: fmt.Sprintf("unable to load model file: %s", filename)}
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, SvmError{Kind: ErrLoadFailed, Message: fmt.Sprintf("not a gzip compressed model file: %s: %s", filename, err)}
+	}
+	defer zr.Close()
+
+	return LoadFrom(zr)
+}
+
+// Clone returns an independent copy of the model, with its own C memory and
+// finalizer, by serializing and reloading it. A clone of a trained model no
+// longer references the training problem.
+func (mdl *SvmModel) Clone() (*SvmModel, error) {
+	if err := checkModel(mdl, "clone an svm model"); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if _, err := mdl.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+
+	return LoadFrom(&buf)
+}
+
+// GobEncode implements gob.GobEncoder by encoding the model in the LIBSVM
+// model file format
+func (mdl *SvmModel) GobEncode() ([]byte, error) {
+	if err := checkModel(mdl, "gob encode an svm model"); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if _, err := mdl.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder, replacing the model with one decoded
+// from data written by GobEncode and freeing any model it held before.
+// The model may be part of a larger value, such as a struct field; it is
+// freed by a finalizer once unreachable, as models returned by Load are.
+func (mdl *SvmModel) GobDecode(data []byte) error {
+	loaded, err := LoadFrom(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	mdl.mu.Lock()
+	defer mdl.mu.Unlock()
+
+	if mdl.object != nil && atomic.LoadInt32(&mdl.freed) == 0 {
+		mdl.free()
+	}
+
+	mdl.object, mdl.handle = loaded.object, loaded.handle
+	loaded.object, loaded.handle = nil, nil
+	mdl.maxIndex = loaded.maxIndex
+	atomic.StoreInt32(&mdl.freed, 0)
+	return nil
+}
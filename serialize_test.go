@@ -0,0 +1,198 @@
+package libsvm
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteToLoadFrom(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	var buf bytes.Buffer
+	n, err := mdl.WriteTo(&buf)
+	if err != nil {
+		t.Fatal("WriteTo error was non-nil", err)
+	}
+
+	if n != int64(buf.Len()) || n == 0 {
+		t.Error("WriteTo reported", n, "bytes but wrote", buf.Len())
+	}
+
+	loaded, err := LoadFrom(&buf)
+	if err != nil {
+		t.Fatal("LoadFrom error was non-nil", err)
+	}
+
+	_, examples := readDenseData(t, "testdata/a1a", 123)
+	for _, ex := range examples[:50] {
+		exa := NewExample(1, ex)
+		want, _ := mdl.Predict(exa)
+		got, _ := loaded.Predict(exa)
+		exa.Free()
+
+		if want != got {
+			t.Errorf("Prediction after round trip was %f, expected %f", got, want)
+		}
+	}
+}
+
+func TestGzipRoundTrip(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	fn := filepath.Join(t.TempDir(), "a1a.model.gz")
+	if err := mdl.SaveGz(fn); err != nil {
+		t.Fatal("SaveGz error was non-nil", err)
+	}
+
+	compressed, _ := os.Stat(fn)
+	plain, _ := os.Stat("testdata/a1a.model")
+	if compressed.Size() >= plain.Size() {
+		t.Errorf("Compressed model is %d bytes, plain model is %d", compressed.Size(), plain.Size())
+	}
+
+	loaded, err := LoadGz(fn)
+	if err != nil {
+		t.Fatal("LoadGz error was non-nil", err)
+	}
+	defer FreeModel(loaded)
+
+	_, examples := readDenseData(t, "testdata/a1a", 123)
+	for _, ex := range examples[:100] {
+		want, _ := mdl.PredictDense(ex)
+		got, _ := loaded.PredictDense(ex)
+		if want != got {
+			t.Errorf("Prediction after gzip round trip was %f, expected %f", got, want)
+		}
+	}
+
+	if _, err := LoadGz("testdata/a1a.model"); err == nil {
+		t.Error("Expected an error loading an uncompressed model with LoadGz")
+	}
+}
+
+func TestClone(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	clone, err := mdl.Clone()
+	if err != nil {
+		t.Fatal("Clone error was non-nil", err)
+	}
+	defer FreeModel(clone)
+
+	_, examples := readDenseData(t, "testdata/a1a", 123)
+	want := make([]float64, 50)
+	for i, ex := range examples[:50] {
+		want[i], _ = mdl.PredictDense(ex)
+		if got, _ := clone.PredictDense(ex); got != want[i] {
+			t.Errorf("Clone predicted %f, expected %f", got, want[i])
+		}
+	}
+
+	if err := FreeModel(mdl); err != nil {
+		t.Fatal("FreeModel error was non-nil", err)
+	}
+
+	for i, ex := range examples[:50] {
+		got, err := clone.PredictDense(ex)
+		if err != nil || got != want[i] {
+			t.Errorf("Clone predicted %f (%v) after freeing the original, expected %f", got, err, want[i])
+		}
+	}
+
+	var empty *SvmModel
+	if _, err := empty.Clone(); err == nil {
+		t.Error("Expected an error cloning a nil model")
+	}
+}
+
+func TestGobRoundTrip(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	type registryEntry struct {
+		Name  string
+		Model *SvmModel
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(registryEntry{Name: "a1a", Model: mdl}); err != nil {
+		t.Fatal("Gob encode error was non-nil", err)
+	}
+
+	var decoded registryEntry
+	if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+		t.Fatal("Gob decode error was non-nil", err)
+	}
+	defer FreeModel(decoded.Model)
+
+	if decoded.Name != "a1a" || decoded.Model.NrClass() != 2 {
+		t.Fatal("Unexpected decoded entry", decoded.Name, decoded.Model.NrClass())
+	}
+
+	_, examples := readDenseData(t, "testdata/a1a", 123)
+	for _, ex := range examples[:50] {
+		want, _ := mdl.PredictDense(ex)
+		if got, _ := decoded.Model.PredictDense(ex); got != want {
+			t.Errorf("Decoded model predicted %f, expected %f", got, want)
+		}
+	}
+}
+
+func TestGobDecodeByValue(t *testing.T) {
+	mdl, err := Load("testdata/a1a.model")
+	if err != nil {
+		t.Fatal("Model load error was non-nil", err)
+	}
+
+	type registryEntry struct {
+		Name  string
+		Model *SvmModel
+	}
+
+	type inlineEntry struct {
+		Name  string
+		Model SvmModel
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(registryEntry{Name: "a1a", Model: mdl}); err != nil {
+		t.Fatal("Gob encode error was non-nil", err)
+	}
+
+	var decoded inlineEntry
+	if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+		t.Fatal("Gob decode error was non-nil", err)
+	}
+
+	if decoded.Model.NrClass() != 2 {
+		t.Fatal("Unexpected decoded model", decoded.Model.NrClass())
+	}
+
+	// the model sits inside decoded, so closing it must not touch finalizers
+	if err := decoded.Model.Close(); err != nil {
+		t.Error("Close error was non-nil", err)
+	}
+
+	if _, err := decoded.Model.PredictDense([]float64{1}); !errors.Is(err, ErrFreed) {
+		t.Error("Expected a freed error after Close, got", err)
+	}
+
+	runtime.GC()
+}
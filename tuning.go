@@ -0,0 +1,109 @@
+package libsvm
+
+/*
+#include <svm.h>
+*/
+import "C"
+
+import (
+	"context"
+)
+
+// GridSearch cross validates every combination of C and gamma, in the manner
+// of LIBSVM's grid.py, and returns the combination with the best accuracy.
+// All other settings are taken from base, which is not modified. The gamma
+// sweep is skipped for LINEAR kernels, and when gammaRange is empty, in which
+// case bestGamma is the gamma of base. The search stops early with the
+// context's error if ctx is cancelled; ties keep the first combination tried.
+func GridSearch(ctx context.Context, prob SvmProblem, base SvmParameter, cRange, gammaRange []float64, nrFold int) (bestC, bestGamma, bestScore float64, err error) {
+	if len(cRange) == 0 {
+		return 0, 0, 0, SvmError{Message: "empty C range when attempting a grid search"}
+	}
+
+	if prob.object == nil || base.object == nil {
+		return 0, 0, 0, SvmError{Message: "nil problem or parameter when attempting a grid search"}
+	}
+
+	param := copyParam(base)
+	defer freeParamCopy(param)
+
+	gammas := gammaRange
+	if KernelType(base.object.kernel_type) == LINEAR || len(gammas) == 0 {
+		gammas = []float64{float64(base.object.gamma)}
+	}
+
+	labels := problemLabels(prob)
+	bestScore = -1
+	for _, c := range cRange {
+		for _, gamma := range gammas {
+			if err := ctx.Err(); err != nil {
+				return 0, 0, 0, err
+			}
+
+			param.SetC(c)
+			param.SetGamma(gamma)
+
+			target, err := CrossValidation(prob, *param, nrFold)
+			if err != nil {
+				return 0, 0, 0, err
+			}
+
+			score, err := Accuracy(target, labels)
+			if err != nil {
+				return 0, 0, 0, err
+			}
+
+			if score > bestScore {
+				bestC, bestGamma, bestScore = c, gamma, score
+			}
+		}
+	}
+
+	return bestC, bestGamma, bestScore, nil
+}
+
+// AutoKernel cross validates base with each of the candidate kernels and
+// trains a model with the one giving the best accuracy, which is returned
+// along with its score. All other settings, including gamma, degree and
+// coef0, are taken from base, which is not modified. Ties keep the kernel
+// listed first. As with Train, the problem must outlive the returned model.
+func AutoKernel(prob SvmProblem, base SvmParameter, kernels []KernelType, nrFold int) (KernelType, float64, *SvmModel, error) {
+	if len(kernels) == 0 {
+		return 0, 0, nil, SvmError{Message: "no kernels when attempting to choose a kernel"}
+	}
+
+	if prob.object == nil || base.object == nil {
+		return 0, 0, nil, SvmError{Message: "nil problem or parameter when attempting to choose a kernel"}
+	}
+
+	param := copyParam(base)
+	defer freeParamCopy(param)
+
+	labels := problemLabels(prob)
+	best, bestScore := kernels[0], -1.0
+	for _, kernel := range kernels {
+		param.object.kernel_type = C.int(kernel)
+
+		target, err := CrossValidation(prob, *param, nrFold)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+
+		score, err := Accuracy(target, labels)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+
+		if score > bestScore {
+			best, bestScore = kernel, score
+		}
+	}
+
+	param.object.kernel_type = C.int(best)
+	mdl, err := Train(prob, *param)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	return best, bestScore, mdl, nil
+}
@@ -0,0 +1,110 @@
+package libsvm
+
+import (
+	"context"
+	"testing"
+)
+
+// separableData returns two well separated clusters labelled 1 and -1
+func separableData() ([]float64, [][]float64) {
+	var labels []float64
+	var X [][]float64
+	for i := 0; i < 20; i++ {
+		off := float64(i%5) * 0.1
+		labels = append(labels, 1, -1)
+		X = append(X, []float64{2 + off, 2 - off}, []float64{-2 - off, -2 + off})
+	}
+
+	return labels, X
+}
+
+func TestGridSearch(t *testing.T) {
+	labels, X := separableData()
+	prob, err := NewProblem(labels, X)
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	base := NewParameter(C_SVC, RBF)
+	defer FreeParam(base)
+	base.SetGamma(0.5)
+
+	bestC, bestGamma, score, err := GridSearch(context.Background(), *prob, *base, []float64{1, 10}, []float64{0.1, 1}, 2)
+	if err != nil {
+		t.Fatal("GridSearch error was non-nil", err)
+	}
+
+	if score != 1 || bestC != 1 || bestGamma != 0.1 {
+		t.Errorf("Unexpected grid search result C=%f gamma=%f score=%f", bestC, bestGamma, score)
+	}
+
+	if base.object.C != 1 || base.object.gamma != 0.5 {
+		t.Error("GridSearch modified the base parameter")
+	}
+
+	param := NewParameter(C_SVC, RBF)
+	defer FreeParam(param)
+	param.SetC(bestC)
+	param.SetGamma(bestGamma)
+
+	mdl, err := Train(*prob, *param)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	predicted := make([]float64, len(X))
+	for i, x := range X {
+		exa := NewExample(1, x)
+		predicted[i], _ = mdl.Predict(exa)
+		exa.Free()
+	}
+
+	if acc, _ := Accuracy(predicted, labels); acc != score {
+		t.Errorf("Retrained accuracy %f does not reproduce grid search score %f", acc, score)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, _, err := GridSearch(ctx, *prob, *base, []float64{1}, []float64{1}, 2); err != context.Canceled {
+		t.Error("Expected a context cancellation error, got", err)
+	}
+}
+
+func TestAutoKernel(t *testing.T) {
+	labels, X := separableData()
+	prob, err := NewProblem(labels, X)
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	base := NewParameter(C_SVC, RBF)
+	defer FreeParam(base)
+	base.SetGamma(0.5)
+
+	kernels := []KernelType{LINEAR, POLY, RBF}
+	best, score, mdl, err := AutoKernel(*prob, *base, kernels, 2)
+	if err != nil {
+		t.Fatal("AutoKernel error was non-nil", err)
+	}
+	defer FreeModel(mdl)
+
+	// every kernel separates this data perfectly, so LINEAR wins the tie
+	if best != LINEAR || score != 1 {
+		t.Errorf("Expected LINEAR with a score of 1, got %s with %f", best, score)
+	}
+
+	if mdl.Parameter().Kernel() != LINEAR {
+		t.Error("Expected the returned model to use the chosen kernel, got", mdl.Parameter().Kernel())
+	}
+
+	if base.Kernel() != RBF {
+		t.Error("AutoKernel modified the base parameter")
+	}
+
+	if _, _, _, err := AutoKernel(*prob, *base, nil, 2); err == nil {
+		t.Error("Expected an error with no candidate kernels")
+	}
+}
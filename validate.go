@@ -0,0 +1,116 @@
+package libsvm
+
+import "fmt"
+
+// Validate checks the parameters for mistakes that can be caught without a
+// problem, giving faster and more specific feedback than CheckParameter.
+// The returned error names the offending field and matches ErrInvalidParameter.
+// Train, CrossValidation and the functions built on them run it before
+// training, so calling it directly is only needed to check early.
+func (param *SvmParameter) Validate() error {
+	if param == nil || param.object == nil {
+		return SvmError{Kind: ErrInvalidParameter, Message: "nil param when attempting to validate an svm parameter"}
+	}
+
+	svmType := param.SvmType()
+	kernel := param.Kernel()
+
+	switch kernel {
+	case POLY, RBF, SIGMOID:
+		if param.Gamma() < 0 {
+			return invalidParam("gamma", param.Gamma(), fmt.Sprintf("must be >= 0 for the %s kernel", kernel))
+		}
+	}
+
+	if kernel == POLY && param.Degree() < 1 {
+		return invalidParam("degree", float64(param.Degree()), "must be >= 1 for the POLY kernel")
+	}
+
+	switch svmType {
+	case NU_SVC, NU_SVR, ONE_CLASS:
+		if nu := param.Nu(); nu <= 0 || nu > 1 {
+			return invalidParam("nu", nu, fmt.Sprintf("must be in (0, 1] for %s", svmType))
+		}
+	}
+
+	switch svmType {
+	case C_SVC, EPSILON_SVR, NU_SVR:
+		if param.C() <= 0 {
+			return invalidParam("C", param.C(), fmt.Sprintf("must be > 0 for %s", svmType))
+		}
+	}
+
+	if svmType == EPSILON_SVR && param.P() < 0 {
+		return invalidParam("p", param.P(), "must be >= 0 for EPSILON_SVR")
+	}
+
+	if param.Eps() <= 0 {
+		return invalidParam("eps", param.Eps(), "must be > 0")
+	}
+
+	if param.CacheSize() <= 0 {
+		return invalidParam("cache_size", param.CacheSize(), "must be > 0")
+	}
+
+	// LIBSVM only estimates ONE_CLASS probabilities, through density marks,
+	// from 3.30; older versions reject the combination in svm_check_parameter
+	if svmType == ONE_CLASS && param.object.probability != 0 && Version() < 330 {
+		return invalidParam("probability", 1, fmt.Sprintf("ONE_CLASS probability estimates need LIBSVM 330 or newer, %d is linked", Version()))
+	}
+
+	return nil
+}
+
+// Warnings lists the fields that were changed from the NewParameter defaults
+// but that LIBSVM ignores for the chosen svm type and kernel, such as gamma
+// for the LINEAR kernel, which often explains why tuning them has no effect.
+// The notes are advisory only: Train accepts such parameters unchanged.
+func (param *SvmParameter) Warnings() []string {
+	if param == nil || param.object == nil {
+		return nil
+	}
+
+	svmType := param.SvmType()
+	kernel := param.Kernel()
+
+	var notes []string
+	ignored := func(field, where string) {
+		notes = append(notes, fmt.Sprintf("%s is ignored for %s", field, where))
+	}
+
+	if param.Gamma() != 0 && kernel != POLY && kernel != RBF && kernel != SIGMOID {
+		ignored("gamma", kernel.String()+" kernel")
+	}
+
+	if param.Degree() != 3 && kernel != POLY {
+		ignored("degree", kernel.String()+" kernel")
+	}
+
+	if param.Coef0() != 0 && kernel != POLY && kernel != SIGMOID {
+		ignored("coef0", kernel.String()+" kernel")
+	}
+
+	if param.Nu() != 0.5 && svmType != NU_SVC && svmType != NU_SVR && svmType != ONE_CLASS {
+		ignored("nu", svmType.String())
+	}
+
+	if param.C() != 1 && svmType != C_SVC && svmType != EPSILON_SVR && svmType != NU_SVR {
+		ignored("C", svmType.String())
+	}
+
+	if param.P() != 0.1 && svmType != EPSILON_SVR {
+		ignored("p", svmType.String())
+	}
+
+	// only C_SVC scales C by class; the other formulations have no per-class C
+	if param.object.nr_weight > 0 && svmType != C_SVC {
+		ignored("class weights", svmType.String())
+	}
+
+	return notes
+}
+
+// invalidParam builds the error returned by Validate for a bad field
+func invalidParam(field string, value float64, reason string) error {
+	return SvmError{Kind: ErrInvalidParameter, Message: fmt.Sprintf("invalid %s %g: %s", field, value, reason)}
+}
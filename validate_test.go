@@ -0,0 +1,120 @@
+package libsvm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		svmType SvmType
+		kernel  KernelType
+		set     func(*SvmParameter)
+		field   string
+	}{
+		{C_SVC, RBF, func(p *SvmParameter) {}, ""},
+		{C_SVC, RBF, func(p *SvmParameter) { p.SetGamma(-1) }, "gamma"},
+		{C_SVC, POLY, func(p *SvmParameter) { p.SetGamma(-0.5) }, "gamma"},
+		{C_SVC, SIGMOID, func(p *SvmParameter) { p.SetGamma(-2) }, "gamma"},
+		{C_SVC, LINEAR, func(p *SvmParameter) { p.SetGamma(-1) }, ""},
+		{C_SVC, POLY, func(p *SvmParameter) { p.SetDegree(0) }, "degree"},
+		{C_SVC, RBF, func(p *SvmParameter) { p.SetDegree(0) }, ""},
+		{NU_SVC, RBF, func(p *SvmParameter) { p.SetNu(0) }, "nu"},
+		{NU_SVR, RBF, func(p *SvmParameter) { p.SetNu(1.5) }, "nu"},
+		{ONE_CLASS, RBF, func(p *SvmParameter) { p.SetNu(-1) }, "nu"},
+		{ONE_CLASS, RBF, func(p *SvmParameter) { p.SetNu(1) }, ""},
+		{C_SVC, RBF, func(p *SvmParameter) { p.SetC(0) }, "C"},
+		{EPSILON_SVR, RBF, func(p *SvmParameter) { p.SetC(-1) }, "C"},
+		{NU_SVR, RBF, func(p *SvmParameter) { p.SetC(0) }, "C"},
+		{NU_SVC, RBF, func(p *SvmParameter) { p.SetC(0) }, ""},
+		{EPSILON_SVR, RBF, func(p *SvmParameter) { p.SetP(-0.1) }, "p"},
+		{NU_SVR, RBF, func(p *SvmParameter) { p.SetP(-0.1) }, ""},
+		{C_SVC, RBF, func(p *SvmParameter) { p.SetEps(0) }, "eps"},
+		{C_SVC, RBF, func(p *SvmParameter) { p.SetCacheSize(-1) }, "cache_size"},
+		{C_SVC, PRECOMPUTED, func(p *SvmParameter) { p.EnableProbability(true) }, ""},
+	}
+
+	for i, c := range cases {
+		param := NewParameter(c.svmType, c.kernel)
+		c.set(param)
+		err := param.Validate()
+		FreeParam(param)
+
+		if c.field == "" {
+			if err != nil {
+				t.Errorf("Case %d: expected no error, got %v", i, err)
+			}
+			continue
+		}
+
+		if !errors.Is(err, ErrInvalidParameter) || !strings.Contains(err.Error(), "invalid "+c.field+" ") {
+			t.Errorf("Case %d: expected an error naming %s, got %v", i, c.field, err)
+		}
+	}
+}
+
+func TestValidateOneClassProbability(t *testing.T) {
+	param := NewParameter(ONE_CLASS, RBF)
+	defer FreeParam(param)
+	param.EnableProbability(true)
+
+	defer func(version func() int) { libsvmVersion = version }(libsvmVersion)
+	libsvmVersion = func() int { return 330 }
+
+	if err := param.Validate(); err != nil {
+		t.Error("Expected ONE_CLASS probability estimates to validate with LIBSVM 330", err)
+	}
+
+	libsvmVersion = func() int { return 325 }
+
+	err := param.Validate()
+	if !errors.Is(err, ErrInvalidParameter) || !strings.Contains(err.Error(), "ONE_CLASS probability estimates need LIBSVM 330") {
+		t.Error("Expected an error explaining ONE_CLASS probability is unsupported, got", err)
+	}
+
+	prob, err := NewProblem([]float64{1, 1}, [][]float64{{0}, {1}})
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(prob)
+
+	if _, err := Train(*prob, *param); !errors.Is(err, ErrInvalidParameter) || !strings.Contains(err.Error(), "ONE_CLASS probability") {
+		t.Error("Expected Train to reject ONE_CLASS probability estimates, got", err)
+	}
+
+	if _, _, err := TrainWithSummary(*prob, *param); !errors.Is(err, ErrInvalidParameter) {
+		t.Error("Expected TrainWithSummary to reject ONE_CLASS probability estimates, got", err)
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	param := NewParameter(C_SVC, RBF)
+	defer FreeParam(param)
+
+	if w := param.Warnings(); len(w) != 0 {
+		t.Error("Expected no warnings for default parameters, got", w)
+	}
+
+	param.SetDegree(5)
+	param.SetGamma(0.5)
+	w := param.Warnings()
+	if len(w) != 1 || w[0] != "degree is ignored for RBF kernel" {
+		t.Error("Expected a single warning about degree, got", w)
+	}
+
+	linear := NewParameter(NU_SVC, LINEAR)
+	defer FreeParam(linear)
+	linear.SetGamma(0.1)
+	linear.SetC(10)
+	linear.SetClassWeights(map[int]float64{1: 2})
+
+	w = linear.Warnings()
+	if len(w) != 3 || !strings.Contains(strings.Join(w, "; "), "gamma is ignored for LINEAR kernel") {
+		t.Error("Expected warnings about gamma, C and class weights, got", w)
+	}
+
+	if err := linear.Validate(); err != nil {
+		t.Error("Expected parameters with warnings to still validate", err)
+	}
+}
@@ -0,0 +1,83 @@
+package libsvm
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// modelHeaderVersions maps each model file header keyword to the earliest
+// LIBSVM version, in libsvm_version form, that writes and reads it
+var modelHeaderVersions = map[string]int{
+	"svm_type":           300,
+	"kernel_type":        300,
+	"degree":             300,
+	"gamma":              300,
+	"coef0":              300,
+	"nr_class":           300,
+	"total_sv":           300,
+	"rho":                300,
+	"label":              300,
+	"probA":              300,
+	"probB":              300,
+	"nr_sv":              300,
+	"prob_density_marks": 330,
+}
+
+// ModelFileVersion reports the earliest LIBSVM version, in the same form as
+// Version (e.g. 330 for 3.30), able to load the given model file. Model files
+// carry no version number, so it is inferred from the header keywords
+// present; a keyword this package does not know about is reported as an
+// error, since it most likely comes from a newer LIBSVM.
+func ModelFileVersion(filename string) (int, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return 0, SvmError{Kind: ErrLoadFailed, Message: fmt.Sprintf("unable to read model file: %s", filename)}
+	}
+	defer f.Close()
+
+	version := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if fields[0] == "SV" {
+			break
+		}
+
+		v, ok := modelHeaderVersions[fields[0]]
+		if !ok {
+			return 0, SvmError{Kind: ErrLoadFailed, Message: fmt.Sprintf("unrecognised header %q in model file %s, it may have been saved by a newer LIBSVM", fields[0], filename)}
+		}
+
+		if v > version {
+			version = v
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, SvmError{Kind: ErrLoadFailed, Message: fmt.Sprintf("unable to read model file %s: %v", filename, err)}
+	}
+
+	if version == 0 {
+		return 0, SvmError{Kind: ErrLoadFailed, Message: fmt.Sprintf("not a LIBSVM model file: missing svm_type header in %s", filename)}
+	}
+
+	return version, nil
+}
+
+// checkModelVersion warns through the print function when a model file
+// looks like it needs a newer LIBSVM than the one linked, which otherwise
+// only shows up as an unhelpful load failure
+func checkModelVersion(filename string) {
+	version, err := ModelFileVersion(filename)
+	if err != nil {
+		printf("warning: %v\n", err)
+	} else if version > Version() {
+		printf("warning: model file %s needs LIBSVM %d or newer, but %d is linked\n", filename, version, Version())
+	}
+}
@@ -0,0 +1,49 @@
+package libsvm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestModelFileVersion(t *testing.T) {
+	if v, err := ModelFileVersion("testdata/a1a.model"); err != nil || v != 300 {
+		t.Errorf("Expected a1a.model to need version 300, got %d %v", v, err)
+	}
+
+	if v, err := ModelFileVersion("testdata/density.model"); err != nil || v != 330 {
+		t.Errorf("Expected prob_density_marks to need version 330, got %d %v", v, err)
+	}
+
+	if _, err := ModelFileVersion("testdata/toy"); err == nil {
+		t.Error("Expected an error for a data file")
+	}
+
+	raw, err := os.ReadFile("testdata/a1a.model")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fn := filepath.Join(t.TempDir(), "future.model")
+	future := strings.Replace(string(raw), "SV\n", "future_option 1\nSV\n", 1)
+	if err := os.WriteFile(fn, []byte(future), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ModelFileVersion(fn); err == nil || !strings.Contains(err.Error(), "future_option") {
+		t.Error("Expected an error naming the unknown header, got", err)
+	}
+
+	var out strings.Builder
+	SetPrintFunc(func(s string) { out.WriteString(s) })
+	defer SetPrintFunc(nil)
+
+	if mdl, err := Load(fn); err == nil {
+		FreeModel(mdl)
+	}
+
+	if !strings.Contains(out.String(), "warning: ") || !strings.Contains(out.String(), "newer LIBSVM") {
+		t.Error("Expected Load to warn about the newer format, got", out.String())
+	}
+}
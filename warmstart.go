@@ -0,0 +1,66 @@
+package libsvm
+
+// TrainWarmStart approximates incremental learning by training on the
+// support vectors of base together with the examples of newProb, rather than
+// on all the data base was trained on. Examples that were not support
+// vectors rarely become one when a little data is added, so the result is
+// usually close to a full retrain at a fraction of the cost. Only
+// classification and one-class models can be warm started, since model files
+// do not record the targets of regression support vectors, and PRECOMPUTED
+// kernel models cannot be, since their support vectors are only serial
+// numbers. The returned model owns the combined problem, so neither base nor
+// newProb need outlive it.
+func TrainWarmStart(base *SvmModel, newProb SvmProblem, param SvmParameter) (*SvmModel, error) {
+	if err := checkModel(base, "warm start from an svm model"); err != nil {
+		return nil, err
+	}
+
+	if kernel := base.Parameter().Kernel(); kernel == PRECOMPUTED {
+		return nil, SvmError{Message: "PRECOMPUTED kernel models cannot be warm started"}
+	}
+
+	var labels []float64
+	switch t := base.SvmType(); t {
+	case C_SVC, NU_SVC:
+		classes := base.Labels()
+		for i, n := range base.NrSv() {
+			for j := 0; j < n; j++ {
+				labels = append(labels, float64(classes[i]))
+			}
+		}
+	case ONE_CLASS:
+		labels = make([]float64, base.TotalSv())
+		for i := range labels {
+			labels[i] = 1
+		}
+	default:
+		return nil, SvmError{Message: t.String() + " models cannot be warm started, their support vector targets are not stored"}
+	}
+
+	svs := base.SupportVectors()
+	indices := make([][]int, len(svs))
+	values := make([][]float64, len(svs))
+	for i, sv := range svs {
+		for _, p := range sv {
+			indices[i] = append(indices[i], p.Index)
+			values[i] = append(values[i], p.Value)
+		}
+	}
+
+	seed := newSparseProblem(labels, indices, values)
+	defer FreeProblem(seed)
+
+	combined, err := seed.Append(&newProb)
+	if err != nil {
+		return nil, err
+	}
+
+	mdl, err := Train(*combined, param)
+	if err != nil {
+		FreeProblem(combined)
+		return nil, err
+	}
+
+	mdl.own(combined)
+	return mdl, nil
+}
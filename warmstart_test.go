@@ -0,0 +1,78 @@
+package libsvm
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestTrainWarmStart(t *testing.T) {
+	labels, X := separableData()
+	param := NewParameter(C_SVC, LINEAR)
+	defer FreeParam(param)
+
+	old, err := NewProblem(labels[:32], X[:32])
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(old)
+
+	base, err := Train(*old, *param)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(base)
+
+	added, err := NewProblem(labels[32:], X[32:])
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+
+	warm, err := TrainWarmStart(base, *added, *param)
+	if err != nil {
+		t.Fatal("TrainWarmStart error was non-nil", err)
+	}
+	defer FreeModel(warm)
+
+	// the warm started model owns its training data
+	FreeProblem(added)
+
+	all, err := NewProblem(labels, X)
+	if err != nil {
+		t.Fatal("NewProblem error was non-nil", err)
+	}
+	defer FreeProblem(all)
+
+	full, err := Train(*all, *param)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(full)
+
+	rnd := rand.New(rand.NewSource(3))
+	differ := 0
+	for i := 0; i < 200; i++ {
+		x := []float64{rnd.Float64()*6 - 3, rnd.Float64()*6 - 3}
+		a, _ := warm.PredictDense(x)
+		b, _ := full.PredictDense(x)
+		if a != b {
+			differ++
+		}
+	}
+
+	if differ > 10 {
+		t.Errorf("%d of 200 warm started predictions differed from a full retrain", differ)
+	}
+
+	svr := NewParameter(EPSILON_SVR, LINEAR)
+	defer FreeParam(svr)
+
+	reg, err := Train(*all, *svr)
+	if err != nil {
+		t.Fatal("Train error was non-nil", err)
+	}
+	defer FreeModel(reg)
+
+	if _, err := TrainWarmStart(reg, *all, *svr); err == nil {
+		t.Error("Expected an error warm starting a regression model")
+	}
+}